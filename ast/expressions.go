@@ -3,6 +3,7 @@ package ast
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/ollybritton/aqa/token"
@@ -31,17 +32,22 @@ func (i *Identifier) String() string {
 	return out.String()
 }
 
-// IntegerLiteral represents an integer value in the AST.
+// IntegerLiteral represents an integer value in the AST. Big is only set if the integer is too large for an int64.
 // Example: `5`
 // General: `{token.INT}`
 type IntegerLiteral struct {
 	Tok   token.Token // the token.INT token.
 	Value int64
+	Big   *big.Int
 }
 
 func (il *IntegerLiteral) expressionNode()    {}
 func (il *IntegerLiteral) Token() token.Token { return il.Tok }
 func (il *IntegerLiteral) String() string {
+	if il.Big != nil {
+		return il.Big.String()
+	}
+
 	return fmt.Sprint(il.Value)
 }
 
@@ -59,6 +65,20 @@ func (fl *FloatLiteral) String() string {
 	return fmt.Sprint(fl.Value)
 }
 
+// DecimalLiteral represents an exact decimal value in the AST.
+// Example: `0.1d`
+// General: `{token.DECIMAL}`
+type DecimalLiteral struct {
+	Tok   token.Token // the token.DECIMAL token.
+	Value *big.Rat
+}
+
+func (dl *DecimalLiteral) expressionNode()    {}
+func (dl *DecimalLiteral) Token() token.Token { return dl.Tok }
+func (dl *DecimalLiteral) String() string {
+	return dl.Tok.Literal
+}
+
 // BooleanLiteral represents a boolean in the AST.
 // Example: `true`
 // General: `{token.TRUE or token.FALSE}`
@@ -145,6 +165,37 @@ func (sc *SubroutineCall) String() string {
 	return out.String()
 }
 
+// KeywordArgument represents an argument which is passed to a subroutine by the name of the parameter it is for. It can
+// only appear inside the arguments of a subroutine call.
+// Example: `greeting <- "Hi"` in `greet(greeting <- "Hi", name <- "Sam")`
+// General: `{ident} <- {expression}`
+type KeywordArgument struct {
+	Tok   token.Token // The token.ASSIGN token.
+	Name  *Identifier
+	Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode()    {}
+func (ka *KeywordArgument) Token() token.Token { return ka.Tok }
+func (ka *KeywordArgument) String() string {
+	return ka.Name.String() + " <- " + ka.Value.String()
+}
+
+// SpreadExpression represents an array being passed to a subroutine as separate arguments. It can only appear inside
+// the arguments of a subroutine call.
+// Example: `...scores` in `SUM(...scores)`
+// General: `...{expression}`
+type SpreadExpression struct {
+	Tok   token.Token // The token.ELLIPSIS token.
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()    {}
+func (se *SpreadExpression) Token() token.Token { return se.Tok }
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
+
 // StringLiteral represents a string inside the program.
 // Example: `"hello"`, `'Dave\'s mom was sad'`
 // General: `{'|"}{characters}{'|"}`
@@ -165,6 +216,36 @@ func (sl *StringLiteral) String() string {
 	return out.String()
 }
 
+// InterpolatedString represents a string with expressions embedded inside it. There is always one more string than
+// there are expressions, and the two are interleaved starting with the first string.
+// Example: `f"Score: {score}/{total}"`
+// General: `f"{characters}{{expression}}{characters}..."`
+type InterpolatedString struct {
+	Tok         token.Token // The token.FSTRING_HEAD token.
+	Strings     []string
+	Expressions []Expression
+}
+
+func (is *InterpolatedString) expressionNode()    {}
+func (is *InterpolatedString) Token() token.Token { return is.Tok }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("f\"")
+
+	for i, str := range is.Strings {
+		out.WriteString(str)
+
+		if i < len(is.Expressions) {
+			out.WriteString("{" + is.Expressions[i].String() + "}")
+		}
+	}
+
+	out.WriteString("\"")
+
+	return out.String()
+}
+
 // ArrayLiteral represents an array inside the AST.
 type ArrayLiteral struct {
 	Tok      token.Token // the '[' token
@@ -210,6 +291,44 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// SliceExpression represents taking a range of elements from an array or string within the AST. Any of the bounds can
+// be left out, in which case they are nil.
+// Example: `arr[1:4]`, `s[:3]`, `arr[::-1]`
+// General: `{expression}[{expression}:{expression}:{expression}]`
+type SliceExpression struct {
+	Tok   token.Token // The [ token
+	Left  Expression
+	Start Expression
+	End   Expression
+	Step  Expression
+}
+
+func (se *SliceExpression) expressionNode()    {}
+func (se *SliceExpression) Token() token.Token { return se.Tok }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	bound := func(exp Expression) string {
+		if exp == nil {
+			return ""
+		}
+
+		return exp.String()
+	}
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	out.WriteString(bound(se.Start) + ":" + bound(se.End))
+	if se.Step != nil {
+		out.WriteString(":" + se.Step.String())
+	}
+	out.WriteString("]")
+	out.WriteString(")")
+
+	return out.String()
+}
+
 // HashLiteral represents a hashmap inside the AST.
 type HashLiteral struct {
 	Tok   token.Token // The token.MAP token.
@@ -233,6 +352,31 @@ func (hl *HashLiteral) String() string {
 	return out.String()
 }
 
+// SetLiteral represents a set inside the AST. Duplicate elements are allowed here and removed when it is evaluated.
+// Example: `SET {1, 2, 3}`
+// General: `SET {{expression}, {expression}...}`
+type SetLiteral struct {
+	Tok      token.Token // The token.SET token.
+	Elements []Expression
+}
+
+func (sl *SetLiteral) expressionNode()    {}
+func (sl *SetLiteral) Token() token.Token { return sl.Tok }
+func (sl *SetLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range sl.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("SET {")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
 // DotExpression is the use of the dot (.) operator on two operator. It gets the member associated object
 // from a module.
 type DotExpression struct {
@@ -252,3 +396,95 @@ func (de *DotExpression) String() string {
 
 	return out.String()
 }
+
+// ConditionalExpression represents an IF used as an expression, which evaluates to one of two values. Only the chosen
+// value is evaluated.
+// Example: `IF x > 0 THEN "pos" ELSE "neg"`
+// General: `IF {expression} THEN {expression} ELSE {expression}`
+type ConditionalExpression struct {
+	Tok         token.Token // The token.IF token.
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode()    {}
+func (ce *ConditionalExpression) Token() token.Token { return ce.Tok }
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(IF ")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" THEN ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" ELSE ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// NewExpression represents the creation of a new instance of a class.
+// Example: `NEW Dog("Rex")`
+// General: `NEW {expression}({expression}, {expression}...)`
+type NewExpression struct {
+	Tok       token.Token // The token.NEW token.
+	Class     Expression
+	Arguments []Expression
+}
+
+func (ne *NewExpression) expressionNode()    {}
+func (ne *NewExpression) Token() token.Token { return ne.Tok }
+func (ne *NewExpression) String() string {
+	args := []string{}
+	for _, a := range ne.Arguments {
+		args = append(args, a.String())
+	}
+
+	return "NEW " + ne.Class.String() + "(" + strings.Join(args, ", ") + ")"
+}
+
+// SuperExpression represents the parent class of the class whose method is running, which is used to call the
+// methods the class has overridden.
+// Example: `SUPER` in `SUPER.NEW(name)`
+// General: `SUPER`
+type SuperExpression struct {
+	Tok token.Token // The token.SUPER token.
+}
+
+func (se *SuperExpression) expressionNode()    {}
+func (se *SuperExpression) Token() token.Token { return se.Tok }
+func (se *SuperExpression) String() string     { return "SUPER" }
+
+// FunctionLiteral represents an anonymous function inside the AST. Unlike subroutines, they are expressions and so can
+// be passed straight into other subroutines. The value of the last statement in the body is returned.
+// Example: `FN(x) { x + 5 }`
+// General: `FN({ident}, {ident}...) { {statements} }`
+type FunctionLiteral struct {
+	Tok        token.Token // The token.FN token.
+	Parameters []*Parameter
+	Body       *BlockStatement
+	Generator  bool // True if the body contains a YIELD, so that calling the FN returns a generator.
+}
+
+func (fl *FunctionLiteral) expressionNode()    {}
+func (fl *FunctionLiteral) Token() token.Token { return fl.Tok }
+func (fl *FunctionLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range fl.Parameters {
+		params = append(params, p.String())
+	}
+
+	body := []string{}
+	for _, s := range fl.Body.Statements {
+		body = append(body, s.String())
+	}
+
+	out.WriteString(fl.Tok.Literal)
+	out.WriteString("(" + strings.Join(params, ", ") + ") ")
+	out.WriteString("{ " + strings.Join(body, "; ") + " }")
+
+	return out.String()
+}
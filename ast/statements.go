@@ -14,6 +14,8 @@ type VariableAssignment struct {
 	Tok   token.Token // the token.ASSIGN token.
 	Name  *Identifier
 	Value Expression
+
+	Doc string // The documentation comment directly above a constant, if there is one.
 }
 
 func (va *VariableAssignment) statementNode()     {}
@@ -28,6 +30,171 @@ func (va *VariableAssignment) String() string {
 	return out.String()
 }
 
+// MultipleAssignment represents assigning to several variables at once. All of the values are evaluated before any of
+// the variables are assigned, so `a, b <- b, a` swaps a and b. A single value must be an array, which is unpacked
+// into the variables.
+// Example: `q, r <- divmod(17, 5)`
+// General: `{ident}, {ident}... <- {expression}, {expression}...`
+type MultipleAssignment struct {
+	Tok    token.Token // the token.ASSIGN token.
+	Names  []*Identifier
+	Values []Expression
+}
+
+func (ma *MultipleAssignment) statementNode()     {}
+func (ma *MultipleAssignment) Token() token.Token { return ma.Tok }
+func (ma *MultipleAssignment) String() string {
+	names := []string{}
+	for _, name := range ma.Names {
+		names = append(names, name.String())
+	}
+
+	values := []string{}
+	for _, value := range ma.Values {
+		values = append(values, value.String())
+	}
+
+	return strings.Join(names, ", ") + " <- " + strings.Join(values, ", ")
+}
+
+// FieldAssignment represents the process of assignment to a field of a record in the AST.
+// Example: `car.make <- "Tesla"`
+// General: `{expression}.{ident} <- {expression}`
+type FieldAssignment struct {
+	Tok    token.Token // the token.ASSIGN token.
+	Object Expression
+	Field  *Identifier
+	Value  Expression
+}
+
+func (fa *FieldAssignment) statementNode()     {}
+func (fa *FieldAssignment) Token() token.Token { return fa.Tok }
+func (fa *FieldAssignment) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(fa.Object.String())
+	out.WriteString(".")
+	out.WriteString(fa.Field.String())
+	out.WriteString(" <- ")
+	out.WriteString(fa.Value.String())
+
+	return out.String()
+}
+
+// BreakStatement represents a statement which exits the innermost loop early.
+// Example: `BREAK`
+// General: `BREAK`
+type BreakStatement struct {
+	Tok token.Token // the token.BREAK token.
+}
+
+func (bs *BreakStatement) statementNode()     {}
+func (bs *BreakStatement) Token() token.Token { return bs.Tok }
+func (bs *BreakStatement) String() string     { return "BREAK" }
+
+// ContinueStatement represents a statement which skips to the next iteration of the innermost loop.
+// Example: `CONTINUE`
+// General: `CONTINUE`
+type ContinueStatement struct {
+	Tok token.Token // the token.CONTINUE token.
+}
+
+func (cs *ContinueStatement) statementNode()     {}
+func (cs *ContinueStatement) Token() token.Token { return cs.Tok }
+func (cs *ContinueStatement) String() string     { return "CONTINUE" }
+
+// TryStatement represents a block of statements whose errors are caught and handled by another block. The caught error
+// is assigned to the identifier after CATCH, which is optional.
+// Example:
+//   TRY
+//     age <- STRING_TO_INT(USERINPUT)
+//   CATCH e
+//     OUTPUT e.message
+//   ENDTRY
+// General:
+//   TRY
+//     {statements}
+//   CATCH {IDENT}
+//     {statements}
+//   ENDTRY
+type TryStatement struct {
+	Tok       token.Token // the token.TRY token.
+	Body      *BlockStatement
+	ErrorName *Identifier // nil if the error isn't given a name.
+	Handler   *BlockStatement
+}
+
+func (ts *TryStatement) statementNode()     {}
+func (ts *TryStatement) Token() token.Token { return ts.Tok }
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("TRY\n")
+	out.WriteString(ts.Body.String())
+	out.WriteString("\nCATCH")
+
+	if ts.ErrorName != nil {
+		out.WriteString(" " + ts.ErrorName.String())
+	}
+
+	out.WriteString("\n")
+	out.WriteString(ts.Handler.String())
+	out.WriteString("\nENDTRY")
+
+	return out.String()
+}
+
+// ThrowStatement represents a statement which raises an error. The value is either the message of the error, or an
+// error which was caught earlier.
+// Example: `THROW "age must be positive"`
+// General: `THROW {expression}`
+type ThrowStatement struct {
+	Tok   token.Token // the token.THROW token.
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()     {}
+func (ts *ThrowStatement) Token() token.Token { return ts.Tok }
+func (ts *ThrowStatement) String() string     { return "THROW " + ts.Value.String() }
+
+// IndexAssignment represents the process of assignment to an element of an array or map in the AST.
+// Example: `grid[1][2] <- "X"`
+// General: `{expression}[{expression}] <- {expression}`
+type IndexAssignment struct {
+	Tok   token.Token // the token.ASSIGN token.
+	Left  Expression
+	Index Expression
+	Value Expression
+}
+
+func (ia *IndexAssignment) statementNode()     {}
+func (ia *IndexAssignment) Token() token.Token { return ia.Tok }
+func (ia *IndexAssignment) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ia.Left.String())
+	out.WriteString("[")
+	out.WriteString(ia.Index.String())
+	out.WriteString("]")
+	out.WriteString(" <- ")
+	out.WriteString(ia.Value.String())
+
+	return out.String()
+}
+
+// YieldStatement represents a YIELD inside a subroutine or FN, which makes calling it return a generator that produces
+// each value yielded in turn.
+// Example: `YIELD i * i`
+// General: `YIELD {expression}`
+type YieldStatement struct {
+	Tok   token.Token // The token.YIELD token.
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()     {}
+func (ys *YieldStatement) Token() token.Token { return ys.Tok }
+func (ys *YieldStatement) String() string     { return "YIELD " + ys.Value.String() }
+
 // ReturnStatement represents a return statement from a function or subroutine within a program.
 // Example: `return a`
 // General: `return {expression}`
@@ -150,14 +317,18 @@ func (is *IfStatement) String() string {
 //   ENDSUBROUTINE
 // General:
 //   SUBROUTINE
-//   {ident}({ident}, {ident}...)
+//   {ident}({parameter}, {parameter}...)
 //     {statements}
 //   ENDSUBROUTINE
+// Each parameter is either `{ident}`, `{ident} <- {expression}`, which gives it a default value, or `...{ident}`.
 type Subroutine struct {
 	Tok        token.Token // the token.SUBROUTINE token
 	Name       *Identifier
-	Parameters []*Identifier
+	Parameters []*Parameter
 	Body       *BlockStatement
+	Generator  bool // True if the body contains a YIELD, so that calling the subroutine returns a generator.
+
+	Doc string // The documentation comment directly above the subroutine, if there is one.
 }
 
 func (s *Subroutine) statementNode()     {}
@@ -225,15 +396,17 @@ func (ws *WhileStatement) String() string {
 //     a <- a + 1
 //   ENDFOR
 // General:
-//   FOR {IDENT} <- {INT} TO {INT}
+//   FOR {IDENT} <- {INT} TO {INT} STEP {INT}
 //     {STATEMENTS}
 //   ENDFOR
+// The STEP is optional, and is nil if it is not given.
 type ForStatement struct {
 	Tok token.Token // the token.FOR statement.
 
 	Ident *Identifier
 	Lower Expression
 	Upper Expression
+	Step  Expression
 	Body  *BlockStatement
 }
 
@@ -248,6 +421,55 @@ func (fs *ForStatement) String() string {
 	out.WriteString(fs.Lower.String())
 	out.WriteString(" TO ")
 	out.WriteString(fs.Upper.String())
+
+	if fs.Step != nil {
+		out.WriteString(" STEP ")
+		out.WriteString(fs.Step.String())
+	}
+
+	out.WriteString("\n")
+
+	out.WriteString(fs.Body.String())
+
+	out.WriteString("\n")
+	out.WriteString("ENDFOR")
+
+	return out.String()
+}
+
+// ForInStatement represents a for statement which loops over the items of a collection within the program.
+// Example:
+//   FOR item IN [1, 2, 3]
+//     OUTPUT item
+//   ENDFOR
+// General:
+//   FOR {IDENT}, {IDENT}... IN {EXPRESSION}
+//     {STATEMENTS}
+//   ENDFOR
+// With several identifiers, each item is unpacked like a multiple assignment. For a map, the items are its key-value
+// pairs.
+type ForInStatement struct {
+	Tok token.Token // the token.FOR token.
+
+	Idents   []*Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode()     {}
+func (fs *ForInStatement) Token() token.Token { return fs.Tok }
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	idents := []string{}
+	for _, ident := range fs.Idents {
+		idents = append(idents, ident.String())
+	}
+
+	out.WriteString("FOR ")
+	out.WriteString(strings.Join(idents, ", "))
+	out.WriteString(" IN ")
+	out.WriteString(fs.Iterable.String())
 	out.WriteString("\n")
 
 	out.WriteString(fs.Body.String())
@@ -278,6 +500,198 @@ func (rs *RepeatStatement) String() string {
 	return out.String()
 }
 
+// Parameter represents a single parameter of a subroutine or FN.
+// Default is nil if the parameter has no default value. A rest parameter, such as `...values`, collects any surplus
+// arguments into an array and must be the last parameter.
+// Example: `greeting <- "Hello"` in `SUBROUTINE greet(name, greeting <- "Hello")`
+type Parameter struct {
+	Name    *Identifier
+	Default Expression
+	Rest    bool
+}
+
+func (p *Parameter) String() string {
+	if p.Rest {
+		return "..." + p.Name.String()
+	}
+
+	if p.Default == nil {
+		return p.Name.String()
+	}
+
+	return p.Name.String() + " <- " + p.Default.String()
+}
+
+// RecordField represents a single field inside a record definition.
+// Type is nil if no type was given for the field.
+type RecordField struct {
+	Name *Identifier
+	Type *Identifier
+}
+
+// RecordDefinition represents the definition of a new record type inside the program.
+// Example:
+//   RECORD Car
+//     make : String
+//     price : Real
+//   ENDRECORD
+// General:
+//   RECORD {IDENT}
+//     {IDENT} : {IDENT}
+//     ...
+//   ENDRECORD
+// The types of the fields are optional. When a field has one, the evaluator checks that the values it holds match it.
+type RecordDefinition struct {
+	Tok    token.Token // the token.RECORD token
+	Name   *Identifier
+	Fields []*RecordField
+}
+
+func (rd *RecordDefinition) statementNode()     {}
+func (rd *RecordDefinition) Token() token.Token { return rd.Tok }
+func (rd *RecordDefinition) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("RECORD ")
+	out.WriteString(rd.Name.String())
+	out.WriteString("\n")
+
+	for _, f := range rd.Fields {
+		out.WriteString("  " + f.Name.String())
+
+		if f.Type != nil {
+			out.WriteString(" : " + f.Type.String())
+		}
+
+		out.WriteString("\n")
+	}
+
+	out.WriteString("ENDRECORD")
+
+	return out.String()
+}
+
+// EnumDefinition represents the definition of a new enum type inside the program.
+// Example:
+//   ENUM Colour
+//     RED, GREEN
+//     BLUE
+//   ENDENUM
+// General:
+//   ENUM {IDENT}
+//     {IDENT}
+//     ...
+//   ENDENUM
+// Members can be separated by commas or newlines.
+type EnumDefinition struct {
+	Tok     token.Token // the token.ENUM token
+	Name    *Identifier
+	Members []*Identifier
+}
+
+func (ed *EnumDefinition) statementNode()     {}
+func (ed *EnumDefinition) Token() token.Token { return ed.Tok }
+func (ed *EnumDefinition) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("ENUM ")
+	out.WriteString(ed.Name.String())
+	out.WriteString("\n")
+
+	for _, m := range ed.Members {
+		out.WriteString("  " + m.String() + "\n")
+	}
+
+	out.WriteString("ENDENUM")
+
+	return out.String()
+}
+
+// ClassDefinition represents the definition of a new class inside the program.
+// Example:
+//   CLASS Dog INHERITS Animal
+//     PRIVATE tricks <- 0
+//     PUBLIC SUBROUTINE NEW(name)
+//       SUPER.NEW(name)
+//     ENDSUBROUTINE
+//   ENDCLASS
+// General:
+//   CLASS {IDENT} INHERITS {IDENT}
+//     {PUBLIC or PRIVATE} {IDENT} <- {expression}
+//     {PUBLIC or PRIVATE} {subroutine}
+//     ...
+//   ENDCLASS
+// INHERITS, the PUBLIC or PRIVATE modifier and the default value of a field are all optional. Members are public
+// unless they are marked PRIVATE. The method called NEW is the constructor.
+type ClassDefinition struct {
+	Tok     token.Token // the token.CLASS token
+	Name    *Identifier
+	Parent  *Identifier // nil if the class doesn't inherit from another.
+	Fields  []*ClassField
+	Methods []*ClassMethod
+}
+
+// ClassField is a field declared inside a class definition.
+type ClassField struct {
+	Name    *Identifier
+	Default Expression // nil if the field has no default value.
+	Private bool
+}
+
+func (cf *ClassField) String() string {
+	out := visibility(cf.Private) + " " + cf.Name.String()
+	if cf.Default != nil {
+		out += " <- " + cf.Default.String()
+	}
+
+	return out
+}
+
+// ClassMethod is a method declared inside a class definition.
+type ClassMethod struct {
+	Subroutine *Subroutine
+	Private    bool
+}
+
+func (cm *ClassMethod) String() string {
+	return visibility(cm.Private) + " " + cm.Subroutine.String()
+}
+
+func visibility(private bool) string {
+	if private {
+		return "PRIVATE"
+	}
+
+	return "PUBLIC"
+}
+
+func (cd *ClassDefinition) statementNode()     {}
+func (cd *ClassDefinition) Token() token.Token { return cd.Tok }
+func (cd *ClassDefinition) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("CLASS ")
+	out.WriteString(cd.Name.String())
+
+	if cd.Parent != nil {
+		out.WriteString(" INHERITS " + cd.Parent.String())
+	}
+
+	out.WriteString("\n")
+
+	for _, f := range cd.Fields {
+		out.WriteString("  " + f.String() + "\n")
+	}
+
+	for _, m := range cd.Methods {
+		out.WriteString("  " + m.String() + "\n")
+	}
+
+	out.WriteString("ENDCLASS")
+
+	return out.String()
+}
+
 // ImportStatement represents an import from another file or folder into the program.
 type ImportStatement struct {
 	Tok token.Token // the token.IMPORT token
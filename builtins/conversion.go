@@ -18,12 +18,12 @@ func BuiltinStringToInt(args ...object.Object) object.Object {
 		return newError("argument to `STRING_TO_INT` not supported, got=%s", args[0].Type())
 	}
 
-	conv, err := strconv.ParseInt(str.Value, 0, 64)
+	conv, err := object.StringToInteger(str)
 	if err != nil {
 		return newError("failed to convert %q to integer in call to `STRING_TO_INT`", str.Value)
 	}
 
-	return &object.Integer{Value: conv}
+	return conv
 }
 
 // BuiltinIntToString will convert an integer object into a string object.
@@ -37,8 +37,7 @@ func BuiltinIntToString(args ...object.Object) object.Object {
 		return newError("argument to `INT_TO_STRING` not supported, got=%s", args[0].Type())
 	}
 
-	conv := strconv.FormatInt(i.Value, 10)
-	return &object.String{Value: conv}
+	return object.IntegerToString(i)
 }
 
 // BuiltinStringToReal converts a string object into a real (floating point) object.
@@ -106,3 +105,55 @@ func BuiltinCodeToChar(args ...object.Object) object.Object {
 
 	return &object.String{Value: string(byte(i.Value))}
 }
+
+// BuiltinStringToDecimal converts a string such as "0.1" or "1/3" into an exact decimal object.
+func BuiltinStringToDecimal(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `STRING_TO_DECIMAL` not supported, got=%s", args[0].Type())
+	}
+
+	conv, err := object.StringToDecimal(str)
+	if err != nil {
+		return newError("failed to convert %q to decimal in call to `STRING_TO_DECIMAL`", str.Value)
+	}
+
+	return conv
+}
+
+// BuiltinRealToDecimal converts a real (floating point) object into a decimal object.
+func BuiltinRealToDecimal(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	f, ok := args[0].(*object.Float)
+	if !ok {
+		return newError("argument to `REAL_TO_DECIMAL` not supported, got=%s", args[0].Type())
+	}
+
+	conv, err := object.FloatToDecimal(f)
+	if err != nil {
+		return newError("failed to convert %s to decimal in call to `REAL_TO_DECIMAL`", f.Inspect())
+	}
+
+	return conv
+}
+
+// BuiltinDecimalToReal converts a decimal object into the nearest real (floating point) object.
+func BuiltinDecimalToReal(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	d, ok := args[0].(*object.Decimal)
+	if !ok {
+		return newError("argument to `DECIMAL_TO_REAL` not supported, got=%s", args[0].Type())
+	}
+
+	return object.DecimalToFloat(d)
+}
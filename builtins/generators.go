@@ -0,0 +1,29 @@
+package builtins
+
+import (
+	"github.com/ollybritton/aqa/object"
+)
+
+// BuiltinNext returns the next value of a generator. Once the generator has no values left, the default is returned if
+// one was given, and otherwise it is an error.
+func BuiltinNext(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	gen, ok := args[0].(*object.Generator)
+	if !ok {
+		return newError("argument to `NEXT` not supported, got=%s", args[0].Type())
+	}
+
+	val, ok := gen.Resume()
+	if ok {
+		return val
+	}
+
+	if len(args) == 2 {
+		return args[1]
+	}
+
+	return newError("generator %s has no more values", gen.Name)
+}
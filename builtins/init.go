@@ -26,10 +26,23 @@ func init() {
 	Builtins["APPEND"] = &object.Builtin{Fn: BuiltinAppend}
 	Builtins["SUM"] = &object.Builtin{Fn: BuiltinSum}
 
+	Builtins["UNION"] = &object.Builtin{Fn: BuiltinUnion}
+	Builtins["INTERSECTION"] = &object.Builtin{Fn: BuiltinIntersection}
+	Builtins["DIFFERENCE"] = &object.Builtin{Fn: BuiltinDifference}
+	Builtins["SET_ADD"] = &object.Builtin{Fn: BuiltinSetAdd}
+	Builtins["SET_REMOVE"] = &object.Builtin{Fn: BuiltinSetRemove}
+	Builtins["ARRAY_TO_SET"] = &object.Builtin{Fn: BuiltinArrayToSet}
+	Builtins["SET_TO_ARRAY"] = &object.Builtin{Fn: BuiltinSetToArray}
+
+	Builtins["NEXT"] = &object.Builtin{Fn: BuiltinNext}
+
 	Builtins["STRING_TO_INT"] = &object.Builtin{Fn: BuiltinStringToInt}
 	Builtins["INT_TO_STRING"] = &object.Builtin{Fn: BuiltinIntToString}
 	Builtins["STRING_TO_REAL"] = &object.Builtin{Fn: BuiltinStringToReal}
 	Builtins["REAL_TO_STRING"] = &object.Builtin{Fn: BuiltinRealToString}
+	Builtins["STRING_TO_DECIMAL"] = &object.Builtin{Fn: BuiltinStringToDecimal}
+	Builtins["REAL_TO_DECIMAL"] = &object.Builtin{Fn: BuiltinRealToDecimal}
+	Builtins["DECIMAL_TO_REAL"] = &object.Builtin{Fn: BuiltinDecimalToReal}
 	Builtins["CHAR_TO_CODE"] = &object.Builtin{Fn: BuiltinCharToCode}
 	Builtins["CODE_TO_CHAR"] = &object.Builtin{Fn: BuiltinCodeToChar}
 
@@ -40,6 +53,7 @@ func init() {
 	Builtins["RANDOM_INT"] = &object.Builtin{Fn: BuiltinRandomInt}
 	Builtins["FLOOR"] = &object.Builtin{Fn: BuiltinFloor}
 	Builtins["CEIL"] = &object.Builtin{Fn: BuiltinCeil}
+	Builtins["ROUND"] = &object.Builtin{Fn: BuiltinRound}
 	Builtins["SQRT"] = &object.Builtin{Fn: BuiltinSqrt}
 
 	Builtins["EXIT"] = &object.Builtin{Fn: BuiltinExit}
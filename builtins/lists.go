@@ -1,10 +1,12 @@
 package builtins
 
 import (
+	"unicode/utf8"
+
 	"github.com/ollybritton/aqa/object"
 )
 
-// BuiltinLen calculates the length of a string or array object.
+// BuiltinLen calculates the length of a string, array, set or enum object.
 func BuiltinLen(args ...object.Object) object.Object {
 	if len(args) != 1 {
 		return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -12,9 +14,13 @@ func BuiltinLen(args ...object.Object) object.Object {
 
 	switch arg := args[0].(type) {
 	case *object.String:
-		return &object.Integer{Value: int64(len(arg.Value))}
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 	case *object.Array:
 		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Set:
+		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.EnumType:
+		return &object.Integer{Value: int64(len(arg.Members))}
 	default:
 		return newError("argument to `LEN` not supported, got=%s", args[0].Type())
 	}
@@ -51,7 +57,7 @@ func builtinPositionString(args ...object.Object) object.Object {
 		}
 	}
 
-	search := typed[0]
+	search := []rune(typed[0])
 	find := typed[1]
 
 	for i := 0; i < len(search); i++ {
@@ -110,11 +116,12 @@ func BuiltinSubstring(args ...object.Object) object.Object {
 		return newError("argument 3 to `SUBSTRING` not supported, got=%s", args[2].Type())
 	}
 
-	if start.Value > end.Value || start.Value < 0 || end.Value < 0 || end.Value > int64(len(str.Value)) {
+	runes := []rune(str.Value)
+	if start.Value > end.Value || start.Value < 0 || end.Value < 0 || end.Value >= int64(len(runes)) {
 		return newError("invalid bounds [%d:%d] in call to SUBSTRING", start.Value, end.Value)
 	}
 
-	return &object.String{Value: str.Value[start.Value : end.Value+1]}
+	return &object.String{Value: string(runes[start.Value : end.Value+1])}
 }
 
 // BuiltinSlice will slice an array object.
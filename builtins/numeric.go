@@ -2,6 +2,7 @@ package builtins
 
 import (
 	"math"
+	"math/big"
 	"math/rand"
 
 	"github.com/ollybritton/aqa/object"
@@ -23,10 +24,28 @@ func BuiltinRandomInt(args ...object.Object) object.Object {
 		return newError("argument 2 to `RANDOM_INT` not supported, got=%s", args[1].Type())
 	}
 
-	val := rand.Intn(int(upper.Value-lower.Value+1)) + int(lower.Value)
-	return &object.Integer{Value: int64(val)}
+	lo, hi := lower.BigInt(), upper.BigInt()
+	if hi.Cmp(lo) < 0 {
+		err := newError("upper bound in call to `RANDOM_INT` is less than the lower bound, got lower=%s, upper=%s", lower.Inspect(), upper.Inspect())
+		err.Kind = object.ARGUMENT_ERROR
+
+		return err
+	}
+
+	// The bounds are handled as big integers, so that neither big bounds nor a range wider than an int64 can overflow.
+	span := new(big.Int).Sub(hi, lo)
+	span.Add(span, big.NewInt(1))
+
+	val := new(big.Int).Rand(rand.New(sharedSource{}), span)
+	return object.NewBigInteger(val.Add(val, lo))
 }
 
+// sharedSource lets big.Int.Rand use the shared random number generator, which is seeded when the builtins are set up.
+type sharedSource struct{}
+
+func (sharedSource) Int63() int64 { return rand.Int63() }
+func (sharedSource) Seed(int64)   {}
+
 // BuiltinFloor will floor a float. It has no effect on integers.
 func BuiltinFloor(args ...object.Object) object.Object {
 	if len(args) != 1 {
@@ -35,7 +54,9 @@ func BuiltinFloor(args ...object.Object) object.Object {
 
 	switch val := args[0].(type) {
 	case *object.Float:
-		return &object.Integer{Value: int64(math.Floor(val.Value))}
+		return object.IntegerFromFloat(math.Floor(val.Value))
+	case *object.Decimal:
+		return object.NewBigInteger(floorRat(val.Value))
 	case *object.Integer:
 		return val
 	default:
@@ -51,7 +72,10 @@ func BuiltinCeil(args ...object.Object) object.Object {
 
 	switch val := args[0].(type) {
 	case *object.Float:
-		return &object.Integer{Value: int64(math.Ceil(val.Value))}
+		return object.IntegerFromFloat(math.Ceil(val.Value))
+	case *object.Decimal:
+		ceil := floorRat(new(big.Rat).Neg(val.Value))
+		return object.NewBigInteger(ceil.Neg(ceil))
 	case *object.Integer:
 		return val
 	default:
@@ -69,12 +93,85 @@ func BuiltinSqrt(args ...object.Object) object.Object {
 	case *object.Float:
 		return &object.Float{Value: math.Sqrt(val.Value)}
 	case *object.Integer:
-		return &object.Float{Value: math.Sqrt(float64(val.Value))}
+		return &object.Float{Value: math.Sqrt(object.IntegerToFloat(val).Value)}
+	case *object.Decimal:
+		return &object.Float{Value: math.Sqrt(object.DecimalToFloat(val).Value)}
 	default:
 		return newError("argument to `FLOOR` not supported, got=%s", args[0].Type())
 	}
 }
 
+// maxRoundPlaces is the largest number of decimal places ROUND accepts, which stops it building enormous powers of ten.
+const maxRoundPlaces = 1000
+
+// BuiltinRound rounds a number to a given number of decimal places. If no places are given, it rounds to an integer.
+// Halves are rounded away from zero, so ROUND(2.5) is 3. Rounding a decimal to some places gives an exact decimal.
+func BuiltinRound(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	places := int64(0)
+	if len(args) == 2 {
+		p, ok := args[1].(*object.Integer)
+		if !ok {
+			return newError("argument 2 to `ROUND` not supported, got=%s", args[1].Type())
+		}
+
+		if p.Value < 0 || p.Value > maxRoundPlaces || p.Big != nil {
+			err := newError("number of decimal places in call to `ROUND` must be between 0 and %d, got=%s", maxRoundPlaces, p.Inspect())
+			err.Kind = object.ARGUMENT_ERROR
+
+			return err
+		}
+
+		places = p.Value
+	}
+
+	switch val := args[0].(type) {
+	case *object.Integer:
+		return val
+	case *object.Float:
+		if len(args) == 1 {
+			return object.IntegerFromFloat(math.Round(val.Value))
+		}
+
+		// Once the scaled value is too big to have a fractional part, rounding it can't change anything. This includes
+		// the scale itself overflowing, which happens above about 308 places.
+		scale := math.Pow(10, float64(places))
+		scaled := val.Value * scale
+		if math.IsInf(scale, 0) || math.Abs(scaled) >= 1<<52 {
+			return val
+		}
+
+		return &object.Float{Value: math.Round(scaled) / scale}
+	case *object.Decimal:
+		scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(places), nil))
+
+		// Round the magnitude by adding a half and flooring, then put the sign back.
+		scaled := new(big.Rat).Mul(new(big.Rat).Abs(val.Value), scale)
+		rounded := floorRat(scaled.Add(scaled, big.NewRat(1, 2)))
+		if val.Value.Sign() < 0 {
+			rounded.Neg(rounded)
+		}
+
+		if len(args) == 1 {
+			return object.NewBigInteger(rounded)
+		}
+
+		result := new(big.Rat).SetInt(rounded)
+		return &object.Decimal{Value: result.Quo(result, scale)}
+	default:
+		return newError("argument 1 to `ROUND` not supported, got=%s", args[0].Type())
+	}
+}
+
+// floorRat returns the largest integer which is less than or equal to a fraction.
+func floorRat(r *big.Rat) *big.Int {
+	// The denominator of a big.Rat is always positive, so Euclidean division rounds towards negative infinity.
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
+
 // BuiltinSum will sum all the items in an array.
 func BuiltinSum(args ...object.Object) object.Object {
 	if len(args) != 1 {
@@ -86,13 +183,13 @@ func BuiltinSum(args ...object.Object) object.Object {
 		return newError("argument to `SUM` not supported, got=%s", args[0].Type())
 	}
 
-	var totalInt int64
+	totalInt := new(big.Int)
 	var totalFloat float64
 
 	for _, e := range val.Elements {
 		switch e := e.(type) {
 		case *object.Integer:
-			totalInt += e.Value
+			totalInt.Add(totalInt, e.BigInt())
 		case *object.Float:
 			totalFloat += e.Value
 		default:
@@ -100,16 +197,16 @@ func BuiltinSum(args ...object.Object) object.Object {
 		}
 	}
 
-	if totalFloat > 0 && totalInt > 0 {
-		return &object.Float{Value: totalFloat + float64(totalInt)}
+	if totalFloat > 0 && totalInt.Sign() > 0 {
+		return &object.Float{Value: totalFloat + object.IntegerToFloat(object.NewBigInteger(totalInt)).Value}
 	}
 
 	if totalFloat != 0 {
 		return &object.Float{Value: totalFloat}
 	}
 
-	if totalInt != 0 {
-		return &object.Integer{Value: totalInt}
+	if totalInt.Sign() != 0 {
+		return object.NewBigInteger(totalInt)
 	}
 
 	return &object.Float{Value: 0.0}
@@ -0,0 +1,126 @@
+package builtins
+
+import (
+	"github.com/ollybritton/aqa/object"
+)
+
+// BuiltinUnion returns the set of elements that are in either of two sets.
+func BuiltinUnion(args ...object.Object) object.Object {
+	left, right, err := twoSets("UNION", args)
+	if err != nil {
+		return err
+	}
+
+	return left.Union(right)
+}
+
+// BuiltinIntersection returns the set of elements that are in both of two sets.
+func BuiltinIntersection(args ...object.Object) object.Object {
+	left, right, err := twoSets("INTERSECTION", args)
+	if err != nil {
+		return err
+	}
+
+	return left.Intersection(right)
+}
+
+// BuiltinDifference returns the set of elements that are in the first set but not the second.
+func BuiltinDifference(args ...object.Object) object.Object {
+	left, right, err := twoSets("DIFFERENCE", args)
+	if err != nil {
+		return err
+	}
+
+	return left.Difference(right)
+}
+
+// BuiltinSetAdd adds elements to a set.
+func BuiltinSetAdd(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError("wrong number of arguments. expected `SET_ADD(set, elements...)` (>2 args), got=%d", len(args))
+	}
+
+	set, ok := args[0].(*object.Set)
+	if !ok {
+		return newError("expecting set as argument 1 to SET_ADD. got=%s", args[0].Type())
+	}
+
+	for _, el := range args[1:] {
+		if !set.Add(el) {
+			return newError("unusable as set element: %s", el.Type())
+		}
+	}
+
+	return set
+}
+
+// BuiltinSetRemove removes elements from a set. Elements which aren't in the set are ignored.
+func BuiltinSetRemove(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError("wrong number of arguments. expected `SET_REMOVE(set, elements...)` (>2 args), got=%d", len(args))
+	}
+
+	set, ok := args[0].(*object.Set)
+	if !ok {
+		return newError("expecting set as argument 1 to SET_REMOVE. got=%s", args[0].Type())
+	}
+
+	for _, el := range args[1:] {
+		set.Remove(el)
+	}
+
+	return set
+}
+
+// BuiltinArrayToSet converts an array into a set, removing any duplicates.
+func BuiltinArrayToSet(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `ARRAY_TO_SET` not supported, got=%s", args[0].Type())
+	}
+
+	set := object.NewSet()
+	for _, el := range array.Elements {
+		if !set.Add(el) {
+			return newError("unusable as set element: %s", el.Type())
+		}
+	}
+
+	return set
+}
+
+// BuiltinSetToArray converts a set into an array, with the elements in the same order as they are printed.
+func BuiltinSetToArray(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	set, ok := args[0].(*object.Set)
+	if !ok {
+		return newError("argument to `SET_TO_ARRAY` not supported, got=%s", args[0].Type())
+	}
+
+	return &object.Array{Elements: set.SortedElements()}
+}
+
+func twoSets(name string, args []object.Object) (*object.Set, *object.Set, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+
+	left, ok := args[0].(*object.Set)
+	if !ok {
+		return nil, nil, newError("argument 1 to `%s` not supported, got=%s", name, args[0].Type())
+	}
+
+	right, ok := args[1].(*object.Set)
+	if !ok {
+		return nil, nil, newError("argument 2 to `%s` not supported, got=%s", name, args[1].Type())
+	}
+
+	return left, right, nil
+}
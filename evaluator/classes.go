@@ -0,0 +1,227 @@
+package evaluator
+
+import (
+	"github.com/ollybritton/aqa/ast"
+	"github.com/ollybritton/aqa/object"
+	"github.com/ollybritton/aqa/token"
+)
+
+func evalClassDefinition(node *ast.ClassDefinition, env *object.Environment) object.Object {
+	if isBuiltin(node.Name.Value) {
+		return newError("cannot assign to builtin: %s", node.Name.Value)
+	}
+
+	class := &object.Class{Name: node.Name.Value, Methods: make(map[string]*object.Method), Env: env}
+
+	if node.Parent != nil {
+		parent := evalIdentifier(node.Parent, env)
+		if isError(parent) {
+			return parent
+		}
+
+		parentClass, ok := parent.(*object.Class)
+		if !ok {
+			return newErrorOfKind(object.TYPE_ERROR, "class %s cannot inherit from %s", node.Name.Value, parent.Type())
+		}
+
+		class.Parent = parentClass
+	}
+
+	declared := make(map[string]bool)
+
+	for _, field := range node.Fields {
+		if declared[field.Name.Value] {
+			return newError("duplicate member %q in class %s", field.Name.Value, node.Name.Value)
+		}
+
+		declared[field.Name.Value] = true
+		class.Fields = append(class.Fields, &object.ClassField{
+			Name: field.Name.Value, Default: field.Default, Private: field.Private, Class: class,
+		})
+	}
+
+	for _, method := range node.Methods {
+		sub := method.Subroutine
+		if declared[sub.Name.Value] {
+			return newError("duplicate member %q in class %s", sub.Name.Value, node.Name.Value)
+		}
+
+		// The documentation of a method is looked up by the name of its class and the method, such as `Dog.speak`.
+		env.SetDoc(node.Name.Value+"."+sub.Name.Value, sub.Doc)
+
+		declared[sub.Name.Value] = true
+		class.Methods[sub.Name.Value] = &object.Method{
+			Name:       sub.Name.Value,
+			Subroutine: &object.Subroutine{Name: sub.Name, Parameters: sub.Parameters, Body: sub.Body, Env: env, Generator: sub.Generator},
+			Private:    method.Private,
+			Class:      class,
+		}
+	}
+
+	err := env.SetConstant(node.Name.Value, class)
+	if isError(err) {
+		return err
+	}
+
+	return nil
+}
+
+func evalNewExpression(node *ast.NewExpression, env *object.Environment) object.Object {
+	class := Eval(node.Class, env)
+	if isError(class) {
+		return class
+	}
+
+	c, ok := class.(*object.Class)
+	if !ok {
+		return newErrorOfKind(object.TYPE_ERROR, "cannot use NEW on %s", class.Type())
+	}
+
+	args, kwargs, err := evalArguments(node.Arguments, env)
+	if err != nil {
+		return err
+	}
+
+	return newInstance(c, args, kwargs, node.Tok, env)
+}
+
+// newInstance creates an instance of a class, setting each field to its default value before calling the constructor
+// with the arguments given. A class without a constructor of its own uses the constructor of its parent.
+func newInstance(class *object.Class, args []object.Object, kwargs []keywordArgument, call token.Token, env *object.Environment) object.Object {
+	instance := &object.Instance{Class: class, Fields: make(map[string]object.Object)}
+
+	for _, field := range class.AllFields() {
+		if field.Default == nil {
+			instance.Fields[field.Name] = NULL
+			continue
+		}
+
+		val := Eval(field.Default, object.NewEnclosedEnvironment(field.Class.Env))
+		if isError(val) {
+			return val
+		}
+
+		instance.Fields[field.Name] = val
+	}
+
+	constructor, ok := class.FindMethod("NEW")
+	if !ok {
+		if len(args)+len(kwargs) != 0 {
+			return newCallError(call, "wrong number of arguments to NEW %s. got=%d, want=0", class.Name, len(args)+len(kwargs))
+		}
+
+		return instance
+	}
+
+	if !canAccess(constructor.Class, constructor.Private, env) {
+		return newError("constructor of class %s is private", class.Name)
+	}
+
+	result := applySubroutine(&object.BoundMethod{Self: instance, Method: constructor}, args, kwargs, call)
+	if isError(result) {
+		return result
+	}
+
+	return instance
+}
+
+// applyMethod calls a method with `self` set to the instance it was looked up on. Inside a method of a class which
+// inherits from another, `super` is set so that the methods of the parent class can be called.
+func applyMethod(bound *object.BoundMethod, args []object.Object, kwargs []keywordArgument, call token.Token) object.Object {
+	extended, err := extendSubroutineEnv(bound.Method.Subroutine, args, kwargs, call)
+	if err != nil {
+		return err
+	}
+
+	extended.SetClass(bound.Method.Class)
+	extended.Set("self", bound.Self)
+	if parent := bound.Method.Class.Parent; parent != nil {
+		extended.Set("super", &object.Super{Self: bound.Self, Class: parent})
+	}
+
+	if bound.Method.Subroutine.Generator {
+		return newGenerator(bound.Method.Name, bound.Method.Subroutine.Body, extended)
+	}
+
+	return execBlockStatement(bound.Method.Subroutine.Body, extended).value
+}
+
+func evalSuperExpression(env *object.Environment) object.Object {
+	val, ok := env.Get("super")
+	if !ok {
+		return newError("SUPER used outside a method of a class which inherits from another")
+	}
+
+	return val
+}
+
+// evalInstanceMember looks up a field or method of an instance. Lookups through SUPER start at the parent class, so
+// that a method can call the version of itself it overrides.
+func evalInstanceMember(instance *object.Instance, class *object.Class, child string, env *object.Environment) object.Object {
+	if field, ok := class.FindField(child); ok {
+		if !canAccess(field.Class, field.Private, env) {
+			return newError("field %q of class %s is private", child, field.Class.Name)
+		}
+
+		return instance.Fields[child]
+	}
+
+	if method, ok := class.FindMethod(child); ok {
+		if !canAccess(method.Class, method.Private, env) {
+			return newError("method %q of class %s is private", child, method.Class.Name)
+		}
+
+		return &object.BoundMethod{Self: instance, Method: method}
+	}
+
+	return newErrorOfKind(object.NAME_ERROR, "class %s has no member %q", class.Name, child)
+}
+
+func evalInstanceFieldAssignment(instance *object.Instance, node *ast.FieldAssignment, env *object.Environment) object.Object {
+	name := node.Field.Value
+
+	field, ok := instance.Class.FindField(name)
+	if !ok {
+		if _, isMethod := instance.Class.FindMethod(name); isMethod {
+			return newError("cannot assign to method %q of class %s", name, instance.Class.Name)
+		}
+
+		return newErrorOfKind(object.NAME_ERROR, "class %s has no field %q", instance.Class.Name, name)
+	}
+
+	if !canAccess(field.Class, field.Private, env) {
+		return newError("field %q of class %s is private", name, field.Class.Name)
+	}
+
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	instance.Fields[name] = val
+
+	return nil
+}
+
+// canAccess reports whether a member declared by a class can be used from the given environment. Public members can
+// be used anywhere, while private members can only be used inside the methods of the class and its subclasses.
+func canAccess(declaredBy *object.Class, private bool, env *object.Environment) bool {
+	if !private {
+		return true
+	}
+
+	class := env.Class()
+
+	return class != nil && class.IsSubclassOf(declaredBy)
+}
+
+func evalInstanceInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	switch operator {
+	case "=", "==":
+		return nativeBoolToBooleanObject(left == right)
+	case "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
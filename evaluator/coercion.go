@@ -6,10 +6,14 @@ import "github.com/ollybritton/aqa/object"
 // without having to deal with converting types yourself.
 //
 // Rules:
-// string + int/float => string + string
-// int/float + string => string + string
+// string + int/float/decimal => string + string
+// int/float/decimal + string => string + string
 // int, float => float & float
 // float, int => float & float
+// int, decimal => decimal & decimal
+// decimal, int => decimal & decimal
+// float, decimal => float & float
+// decimal, float => float & float
 func coerceInfix(left object.Object, operator string, right object.Object) (object.Object, object.Object) {
 	switch {
 	case left.Type() == object.STRING_OBJ && operator == "+" && right.Type() == object.FLOAT_OBJ:
@@ -77,6 +81,44 @@ func coerceInfix(left object.Object, operator string, right object.Object) (obje
 
 		return object.IntegerToFloat(x), y
 
+	case left.Type() == object.STRING_OBJ && operator == "+" && right.Type() == object.DECIMAL_OBJ:
+		x := left.(*object.String)
+		y := right.(*object.Decimal)
+
+		return x, object.DecimalToString(y)
+
+	case left.Type() == object.DECIMAL_OBJ && operator == "+" && right.Type() == object.STRING_OBJ:
+		x := left.(*object.Decimal)
+		y := right.(*object.String)
+
+		return object.DecimalToString(x), y
+
+	// Integers are exact, so they can become decimals without losing anything.
+	case left.Type() == object.DECIMAL_OBJ && right.Type() == object.INTEGER_OBJ:
+		x := left.(*object.Decimal)
+		y := right.(*object.Integer)
+
+		return x, object.IntegerToDecimal(y)
+
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.DECIMAL_OBJ:
+		x := left.(*object.Integer)
+		y := right.(*object.Decimal)
+
+		return object.IntegerToDecimal(x), y
+
+	// Floats aren't exact, so mixing a decimal with one gives a float.
+	case left.Type() == object.DECIMAL_OBJ && right.Type() == object.FLOAT_OBJ:
+		x := left.(*object.Decimal)
+		y := right.(*object.Float)
+
+		return object.DecimalToFloat(x), y
+
+	case left.Type() == object.FLOAT_OBJ && right.Type() == object.DECIMAL_OBJ:
+		x := left.(*object.Float)
+		y := right.(*object.Decimal)
+
+		return x, object.DecimalToFloat(y)
+
 	default:
 		return left, right
 	}
@@ -0,0 +1,147 @@
+package evaluator
+
+import (
+	"github.com/ollybritton/aqa/ast"
+	"github.com/ollybritton/aqa/object"
+)
+
+// completionKind describes how the execution of a statement finished.
+type completionKind int
+
+// Definitions of completion kinds.
+const (
+	normalCompletion   completionKind = iota // Execution carries on with the next statement.
+	returnCompletion                         // A RETURN was executed, so the enclosing subroutine or program stops.
+	breakCompletion                          // A BREAK was executed, so the innermost loop stops.
+	continueCompletion                       // A CONTINUE was executed, so the innermost loop moves to the next iteration.
+	errorCompletion                          // An error occured, so everything stops.
+)
+
+// completion is the result of executing a statement. As well as the value the statement produced, it records whether
+// control should move on to the next statement or be transferred somewhere else, such as out of a loop. Expressions
+// can't transfer control, so they are evaluated to plain objects by Eval instead.
+type completion struct {
+	kind  completionKind
+	value object.Object
+}
+
+// completionOf wraps the result of evaluating a statement which cannot transfer control, such as an assignment.
+func completionOf(obj object.Object) completion {
+	if isError(obj) {
+		return completion{kind: errorCompletion, value: obj}
+	}
+
+	return completion{kind: normalCompletion, value: obj}
+}
+
+// execStatement executes a statement and reports how it finished.
+func execStatement(stmt ast.Statement, env *object.Environment) completion {
+	switch stmt := stmt.(type) {
+	case *ast.BlockStatement:
+		return execBlockStatement(stmt, env)
+
+	case *ast.IfStatement:
+		return execIfStatement(stmt, env)
+
+	case *ast.WhileStatement:
+		return execWhileStatement(stmt, env)
+
+	case *ast.ForStatement:
+		return execForStatement(stmt, env)
+
+	case *ast.ForInStatement:
+		return execForInStatement(stmt, env)
+
+	case *ast.RepeatStatement:
+		return execRepeatStatement(stmt, env)
+
+	case *ast.TryStatement:
+		return execTryStatement(stmt, env)
+
+	case *ast.ReturnStatement:
+		val := Eval(stmt.ReturnValue, env)
+		if isError(val) {
+			return completionOf(val)
+		}
+
+		return completion{kind: returnCompletion, value: val}
+
+	case *ast.YieldStatement:
+		// A generator runs its body using frames, which handle YIELD themselves.
+		return completionOf(newError("YIELD used outside of a generator"))
+
+	case *ast.BreakStatement:
+		return completion{kind: breakCompletion}
+
+	case *ast.ContinueStatement:
+		return completion{kind: continueCompletion}
+	}
+
+	return completionOf(Eval(stmt, env))
+}
+
+// execBlockStatement executes each statement in a block until one of them transfers control.
+func execBlockStatement(block *ast.BlockStatement, env *object.Environment) completion {
+	var result completion
+
+	for _, statement := range block.Statements {
+		result = positionError(execStatement(statement, env), statement)
+
+		if result.kind != normalCompletion {
+			return result
+		}
+	}
+
+	return result
+}
+
+// positionError gives an error which doesn't have a position yet the position of the statement it occured in.
+func positionError(result completion, stmt ast.Statement) completion {
+	if err, ok := result.value.(*object.Error); ok && err.Tok == nil {
+		tok := stmt.Token()
+		err.Tok = &tok
+	}
+
+	return result
+}
+
+// loopStep prepares the next iteration of a loop, such as by checking its condition or setting the loop variable. It
+// is told whether the loop is about to start, and reports whether the body should be run again.
+type loopStep func(first bool) (bool, *object.Error)
+
+// execLoop executes the body of a loop for as long as step allows it.
+func execLoop(body *ast.BlockStatement, env *object.Environment, step loopStep) completion {
+	var result completion
+
+	for first := true; ; first = false {
+		more, err := step(first)
+		if err != nil {
+			return completionOf(err)
+		}
+
+		if !more {
+			return result
+		}
+
+		var stop bool
+		result, stop = loopCompletion(execBlockStatement(body, env))
+		if stop {
+			return result
+		}
+	}
+}
+
+// loopCompletion turns the completion of a single iteration of the body of a loop into the completion as seen from
+// outside of the loop, and reports whether the loop should stop.
+func loopCompletion(result completion) (completion, bool) {
+	switch result.kind {
+	case breakCompletion:
+		return completion{kind: normalCompletion}, true
+	case continueCompletion:
+		return completion{kind: normalCompletion}, false
+	case returnCompletion, errorCompletion:
+		return result, true
+	}
+
+	return result, false
+}
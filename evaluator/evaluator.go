@@ -2,7 +2,9 @@ package evaluator
 
 import (
 	"bufio"
+	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
 	"os"
 	"strconv"
@@ -12,14 +14,27 @@ import (
 	"github.com/ollybritton/aqa/ast"
 	"github.com/ollybritton/aqa/builtins"
 	"github.com/ollybritton/aqa/object"
+	"github.com/ollybritton/aqa/token"
 )
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// Eval evaluates a node, and returns its representation as an object.Object.
+// Eval evaluates a node, and returns its representation as an object.Object. If an error occurs which doesn't have a
+// position yet, it is given the position of the node, so errors point at the innermost node which caused them.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	obj := evalNode(node, env)
+
+	if err, ok := obj.(*object.Error); ok && err.Tok == nil {
+		tok := node.Token()
+		err.Tok = &tok
+	}
+
+	return obj
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
@@ -28,28 +43,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 
-	case *ast.BlockStatement:
-		return evalBlockStatement(node, env)
-
-	case *ast.IfStatement:
-		return evalIfStatement(node, env)
-
-	case *ast.WhileStatement:
-		return evalWhileStatement(node, env)
-
-	case *ast.ForStatement:
-		return evalForStatement(node, env)
-
-	case *ast.RepeatStatement:
-		return evalRepeatStatement(node, env)
-
-	case *ast.ReturnStatement:
-		val := Eval(node.ReturnValue, env)
-		if isError(val) {
-			return val
-		}
-
-		return &object.ReturnValue{Value: val}
+	// Statements which can transfer control are executed by execStatement, which keeps track of how they finished.
+	case *ast.BlockStatement, *ast.IfStatement, *ast.WhileStatement, *ast.ForStatement, *ast.ForInStatement,
+		*ast.RepeatStatement, *ast.ReturnStatement, *ast.BreakStatement, *ast.ContinueStatement, *ast.TryStatement:
+		return execStatement(node.(ast.Statement), env).value
 
 	case *ast.VariableAssignment:
 		val := Eval(node.Value, env)
@@ -66,6 +63,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			if isError(err) {
 				return err
 			}
+
+			env.SetDoc(node.Name.Value, node.Doc)
 		} else {
 			err := env.Set(node.Name.Value, val)
 			if isError(err) {
@@ -82,23 +81,90 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return newError("cannot assign to builtin: %s", node.Name.Value)
 		}
 
-		err := env.Set(name.Value, &object.Subroutine{Parameters: params, Env: env, Body: body, Name: name})
+		err := env.Set(name.Value, &object.Subroutine{Parameters: params, Env: env, Body: body, Name: name, Generator: node.Generator})
+		if isError(err) {
+			return err
+		}
+
+		// This is done even without a documentation comment, so that redefining a subroutine forgets the old one.
+		env.SetDoc(name.Value, node.Doc)
+
+	case *ast.ConditionalExpression:
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return Eval(node.Consequence, env)
+		}
+
+		return Eval(node.Alternative, env)
+
+	case *ast.FunctionLiteral:
+		return &object.Subroutine{Parameters: node.Parameters, Env: env, Body: node.Body, Generator: node.Generator}
+
+	case *ast.MultipleAssignment:
+		return evalMultipleAssignment(node, env)
+
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+
+	case *ast.FieldAssignment:
+		return evalFieldAssignment(node, env)
+
+	case *ast.IndexAssignment:
+		return evalIndexAssignment(node, env)
+
+	case *ast.RecordDefinition:
+		if isBuiltin(node.Name.Value) {
+			return newError("cannot assign to builtin: %s", node.Name.Value)
+		}
+
+		recordType := &object.RecordType{Name: node.Name.Value}
+		for _, field := range node.Fields {
+			if recordType.HasField(field.Name.Value) {
+				return newError("duplicate field %q in record %s", field.Name.Value, node.Name.Value)
+			}
+
+			fieldType := ""
+			if field.Type != nil {
+				fieldType = field.Type.Value
+			}
+
+			recordType.Fields = append(recordType.Fields, field.Name.Value)
+			recordType.FieldTypes = append(recordType.FieldTypes, fieldType)
+		}
+
+		err := env.Set(node.Name.Value, recordType)
 		if isError(err) {
 			return err
 		}
 
+	case *ast.EnumDefinition:
+		return evalEnumDefinition(node, env)
+
+	case *ast.ClassDefinition:
+		return evalClassDefinition(node, env)
+
+	case *ast.NewExpression:
+		return evalNewExpression(node, env)
+
+	case *ast.SuperExpression:
+		return evalSuperExpression(env)
+
 	case *ast.SubroutineCall:
 		expression := Eval(node.Subroutine, env)
 		if isError(expression) {
 			return expression
 		}
 
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		args, kwargs, err := evalArguments(node.Arguments, env)
+		if err != nil {
+			return err
 		}
 
-		return applySubroutine(expression, args)
+		return applySubroutine(expression, args, kwargs, node.Subroutine.Token())
 
 	case *ast.ImportStatement:
 		err := evalImport(node, env)
@@ -109,13 +175,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	// Literals
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return object.NewBigInteger(node.Big)
+		}
+
 		return &object.Integer{Value: node.Value}
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
+	case *ast.DecimalLiteral:
+		return &object.Decimal{Value: node.Value}
 	case *ast.BooleanLiteral:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -145,7 +219,14 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 				return newError("right-hand side of dot expression is not an identifier, got %T.", node.Right)
 			}
 
-			return evalDotExpression(left, ident.Value)
+			return evalDotExpression(left, ident.Value, env)
+		}
+
+		// AND and OR short-circuit, so the right-hand side is only evaluated when it could change the result.
+		if b, ok := left.(*object.Boolean); ok {
+			if (node.Operator == "AND" && !b.Value) || (node.Operator == "OR" && b.Value) {
+				return b
+			}
 		}
 
 		right := Eval(node.Right, env)
@@ -171,61 +252,99 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return evalIndexExpression(left, index)
 
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
+
+	case *ast.SetLiteral:
+		return evalSetLiteral(node, env)
 	}
 
 	return nil
 }
 
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
-	var result object.Object
+	var result completion
 
 	for _, statement := range program.Statements {
-		result = Eval(statement, env)
+		result = execStatement(statement, env)
+		if result.kind != normalCompletion {
+			return result.value
+		}
+	}
 
-		switch result := result.(type) {
-		case *object.ReturnValue:
-			return result.Value
-		case *object.Error:
-			return result
+	return result.value
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		evaluated := Eval(e, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
 		}
+
+		result = append(result, evaluated)
 	}
 
 	return result
 }
 
-func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
-	var result object.Object
+// keywordArgument is an argument which was passed to a subroutine by name, such as `greeting <- "Hi"`.
+type keywordArgument struct {
+	name  string
+	value object.Object
+}
+
+// evalArguments evaluates the arguments of a subroutine call. The arguments passed by name are returned separately from
+// the ones passed by position, and must come after them. Spread arrays are expanded into positional arguments.
+func evalArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, []keywordArgument, object.Object) {
+	args := []object.Object{}
+	kwargs := []keywordArgument{}
 
-	for _, statement := range block.Statements {
-		result = Eval(statement, env)
+	for _, e := range exps {
+		kwarg, ok := e.(*ast.KeywordArgument)
+		if !ok && len(kwargs) != 0 {
+			return nil, nil, newError("positional argument %s follows keyword argument", e.String())
+		}
 
-		if result != nil {
-			rt := result.Type()
+		if spread, ok := e.(*ast.SpreadExpression); ok {
+			evaluated := Eval(spread.Value, env)
+			if isError(evaluated) {
+				return nil, nil, evaluated
+			}
 
-			if rt == object.ERROR_OBJ || rt == object.RETURN_VALUE_OBJ {
-				return result
+			arr, ok := evaluated.(*object.Array)
+			if !ok {
+				return nil, nil, newError("cannot spread %s, need an ARRAY", evaluated.Type())
 			}
+
+			args = append(args, arr.Elements...)
+			continue
 		}
-	}
 
-	return result
-}
+		if !ok {
+			evaluated := Eval(e, env)
+			if isError(evaluated) {
+				return nil, nil, evaluated
+			}
 
-func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
-	var result []object.Object
+			args = append(args, evaluated)
+			continue
+		}
 
-	for _, e := range exps {
-		evaluated := Eval(e, env)
+		evaluated := Eval(kwarg.Value, env)
 		if isError(evaluated) {
-			return []object.Object{evaluated}
+			return nil, nil, evaluated
 		}
 
-		result = append(result, evaluated)
+		kwargs = append(kwargs, keywordArgument{name: kwarg.Name.Value, value: evaluated})
 	}
 
-	return result
+	return args, kwargs, nil
 }
 
 func evalPrefixExpression(operator string, right object.Object) object.Object {
@@ -235,7 +354,7 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
 	default:
-		return newError("unknown operator: %s%s", operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s%s", operator, right.Type())
 	}
 }
 
@@ -246,33 +365,55 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 	case FALSE:
 		return TRUE
 	case NULL:
-		return newError("unknown operator: !null")
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: !null")
 	default:
-		return newError("unknown operator: !%s", right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: !%s", right.Type())
 	}
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
 	switch val := right.(type) {
 	case *object.Integer:
+		if val.Big != nil || val.Value == math.MinInt64 {
+			return object.NewBigInteger(new(big.Int).Neg(val.BigInt()))
+		}
+
 		return &object.Integer{Value: -val.Value}
 	case *object.Float:
 		return &object.Float{Value: -val.Value}
+	case *object.Decimal:
+		return &object.Decimal{Value: new(big.Rat).Neg(val.Value)}
 	default:
-		return newError("unknown operator: -%s", right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: -%s", right.Type())
 	}
 }
 
 func evalInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	if operator == "IN" {
+		return evalInExpression(left, right)
+	}
+
 	left, right = coerceInfix(left, operator, right)
 
 	switch {
+	case left.Type() == object.SET_OBJ && right.Type() == object.SET_OBJ:
+		return evalSetInfixExpression(left, operator, right)
+
+	case left.Type() == object.ENUM_OBJ && right.Type() == object.ENUM_OBJ:
+		return evalEnumInfixExpression(left, operator, right)
+
+	case left.Type() == object.INSTANCE_OBJ && right.Type() == object.INSTANCE_OBJ:
+		return evalInstanceInfixExpression(left, operator, right)
+
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(left, operator, right)
 
 	case left.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ:
 		return evalFloatInfixExpression(left, operator, right)
 
+	case left.Type() == object.DECIMAL_OBJ && right.Type() == object.DECIMAL_OBJ:
+		return evalDecimalInfixExpression(left, operator, right)
+
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(left, operator, right)
 
@@ -289,10 +430,262 @@ func evalInfixExpression(left object.Object, operator string, right object.Objec
 		fallthrough
 
 	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// overflows reports whether applying an operator to two int64s gives a result which doesn't fit in an int64, in which
+// case the calculation has to be done using big integers instead.
+func overflows(a int64, operator string, b int64) bool {
+	switch operator {
+	case "+":
+		sum := a + b
+		return (sum > a) != (b > 0)
+	case "-":
+		diff := a - b
+		return (diff < a) != (b > 0)
+	case "*":
+		if a == 0 || b == 0 {
+			return false
+		}
+
+		product := a * b
+		return product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)
+	case "<<":
+		return b > 0 && (b >= 63 || (a<<uint64(b))>>uint64(b) != a)
+	case "/", "DIV", "div":
+		return a == math.MinInt64 && b == -1
+	default:
+		return false
+	}
+}
+
+// maxShift is the largest number of places an integer can be shifted left by, to stop a typo like `1 << 1000000000`
+// from trying to allocate gigabytes of memory.
+const maxShift = 1 << 20
+
+func evalBigIntegerInfixExpression(left *object.Integer, operator string, right *object.Integer) object.Object {
+	x, y := left.BigInt(), right.BigInt()
+
+	switch operator {
+	case "+":
+		return object.NewBigInteger(x.Add(x, y))
+	case "-":
+		return object.NewBigInteger(x.Sub(x, y))
+	case "*":
+		return object.NewBigInteger(x.Mul(x, y))
+	case "/":
+		if y.Sign() == 0 {
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
+		}
+
+		quo, rem := new(big.Int).QuoRem(x, y, new(big.Int))
+		if rem.Sign() == 0 {
+			return object.NewBigInteger(quo)
+		}
+
+		val, _ := new(big.Float).Quo(new(big.Float).SetInt(x), new(big.Float).SetInt(y)).Float64()
+		return &object.Float{Value: val}
+	case "DIV", "div":
+		if y.Sign() == 0 {
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
+		}
+
+		return object.NewBigInteger(x.Quo(x, y))
+	case "MOD", "mod":
+		if y.Sign() == 0 {
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
+		}
+
+		return object.NewBigInteger(x.Rem(x, y))
+	case ">>", "<<":
+		if y.Sign() < 0 {
+			return newError("cannot perform bit shift using negative number: %s %s %s", x, operator, y)
+		}
+
+		if operator == ">>" {
+			// Shifting right by more bits than any integer could have leaves 0, or -1 for a negative number.
+			if !y.IsInt64() {
+				return object.NewBigInteger(big.NewInt(int64(x.Sign() >> 1)))
+			}
+
+			return object.NewBigInteger(x.Rsh(x, uint(y.Int64())))
+		}
+
+		if !y.IsInt64() || y.Int64() > maxShift {
+			return newError("bit shift too large: %s << %s", x, y)
+		}
+
+		return object.NewBigInteger(x.Lsh(x, uint(y.Int64())))
+
+	case "==", "=":
+		return nativeBoolToBooleanObject(x.Cmp(y) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(x.Cmp(y) != 0)
+	case ">":
+		return nativeBoolToBooleanObject(x.Cmp(y) > 0)
+	case "<":
+		return nativeBoolToBooleanObject(x.Cmp(y) < 0)
+	case ">=":
+		return nativeBoolToBooleanObject(x.Cmp(y) >= 0)
+	case "<=":
+		return nativeBoolToBooleanObject(x.Cmp(y) <= 0)
+
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalDecimalInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	x := left.(*object.Decimal).Value
+	y := right.(*object.Decimal).Value
+
+	switch operator {
+	case "+":
+		return &object.Decimal{Value: new(big.Rat).Add(x, y)}
+	case "-":
+		return &object.Decimal{Value: new(big.Rat).Sub(x, y)}
+	case "*":
+		return &object.Decimal{Value: new(big.Rat).Mul(x, y)}
+	case "/":
+		if y.Sign() == 0 {
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
+		}
+
+		return &object.Decimal{Value: new(big.Rat).Quo(x, y)}
+
+	case "DIV", "div", "MOD", "mod":
+		if y.Sign() == 0 {
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
+		}
+
+		// Like integers, DIV rounds towards zero and MOD takes the sign of the left-hand side.
+		quotient := new(big.Rat).Quo(x, y)
+		whole := new(big.Int).Quo(quotient.Num(), quotient.Denom())
+
+		if operator == "DIV" || operator == "div" {
+			return object.NewBigInteger(whole)
+		}
+
+		product := new(big.Rat).Mul(y, new(big.Rat).SetInt(whole))
+		return &object.Decimal{Value: product.Sub(x, product)}
+
+	case "==", "=":
+		return nativeBoolToBooleanObject(x.Cmp(y) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(x.Cmp(y) != 0)
+	case ">":
+		return nativeBoolToBooleanObject(x.Cmp(y) > 0)
+	case "<":
+		return nativeBoolToBooleanObject(x.Cmp(y) < 0)
+	case ">=":
+		return nativeBoolToBooleanObject(x.Cmp(y) >= 0)
+	case "<=":
+		return nativeBoolToBooleanObject(x.Cmp(y) <= 0)
+
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalEnumInfixExpression compares two enum members. Members of the same enum are ordered by the order they were
+// declared in, but members of different enums can only be tested for equality.
+func evalEnumInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	leftMember := left.(*object.EnumMember)
+	rightMember := right.(*object.EnumMember)
+
+	switch operator {
+	case "==", "=":
+		return nativeBoolToBooleanObject(leftMember == rightMember)
+	case "!=":
+		return nativeBoolToBooleanObject(leftMember != rightMember)
+	}
+
+	if leftMember.EnumType != rightMember.EnumType {
+		return newErrorOfKind(object.TYPE_ERROR, "cannot compare members of different enums: %s %s %s", left.Inspect(), operator, right.Inspect())
+	}
+
+	switch operator {
+	case ">":
+		return nativeBoolToBooleanObject(leftMember.Ordinal > rightMember.Ordinal)
+	case "<":
+		return nativeBoolToBooleanObject(leftMember.Ordinal < rightMember.Ordinal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftMember.Ordinal >= rightMember.Ordinal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftMember.Ordinal <= rightMember.Ordinal)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalSetInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	leftSet := left.(*object.Set)
+	rightSet := right.(*object.Set)
+
+	switch operator {
+	case "|":
+		return leftSet.Union(rightSet)
+	case "&":
+		return leftSet.Intersection(rightSet)
+	case "-":
+		return leftSet.Difference(rightSet)
+	case "==", "=":
+		return nativeBoolToBooleanObject(leftSet.Equals(rightSet))
+	case "!=":
+		return nativeBoolToBooleanObject(!leftSet.Equals(rightSet))
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalInExpression tests whether an object is an element of a set or array, a key of a map, a member of an enum or a
+// substring of a string.
+func evalInExpression(element, container object.Object) object.Object {
+	switch container := container.(type) {
+	case *object.Set:
+		return nativeBoolToBooleanObject(container.Contains(element))
+
+	case *object.Hash:
+		key, ok := element.(object.Hashable)
+		if !ok {
+			return FALSE
+		}
+
+		_, ok = container.Pairs[key.HashKey()]
+		return nativeBoolToBooleanObject(ok)
+
+	case *object.Array:
+		key, ok := element.(object.Hashable)
+		if !ok {
+			return FALSE
+		}
+
+		for _, el := range container.Elements {
+			if other, ok := el.(object.Hashable); ok && other.HashKey() == key.HashKey() {
+				return TRUE
+			}
+		}
+
+		return FALSE
+
+	case *object.EnumType:
+		member, ok := element.(*object.EnumMember)
+		return nativeBoolToBooleanObject(ok && member.EnumType == container)
+
+	case *object.String:
+		str, ok := element.(*object.String)
+		if !ok {
+			return newErrorOfKind(object.TYPE_ERROR, "type mismatch: %s IN STRING", element.Type())
+		}
+
+		return nativeBoolToBooleanObject(strings.Contains(container.Value, str.Value))
 
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s IN %s", element.Type(), container.Type())
 	}
 }
 
@@ -300,6 +693,10 @@ func evalIntegerInfixExpression(left object.Object, operator string, right objec
 	leftInt := left.(*object.Integer)
 	rightInt := right.(*object.Integer)
 
+	if leftInt.Big != nil || rightInt.Big != nil || overflows(leftInt.Value, operator, rightInt.Value) {
+		return evalBigIntegerInfixExpression(leftInt, operator, rightInt)
+	}
+
 	switch operator {
 	case "+":
 		return &object.Integer{Value: leftInt.Value + rightInt.Value}
@@ -309,7 +706,7 @@ func evalIntegerInfixExpression(left object.Object, operator string, right objec
 		return &object.Integer{Value: leftInt.Value * rightInt.Value}
 	case "/":
 		if rightInt.Value == 0 {
-			return newError("division error: division by zero")
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
 		}
 
 		if leftInt.Value%rightInt.Value == 0 {
@@ -334,14 +731,18 @@ func evalIntegerInfixExpression(left object.Object, operator string, right objec
 		return &object.Integer{Value: leftInt.Value << uint64(rightInt.Value)}
 
 	case "DIV", "div":
-		return &object.Integer{Value: int64(
-			math.Floor(float64(leftInt.Value / rightInt.Value)),
-		)}
+		if rightInt.Value == 0 {
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
+		}
+
+		return &object.Integer{Value: leftInt.Value / rightInt.Value}
 
 	case "MOD", "mod":
-		return &object.Integer{Value: int64(
-			leftInt.Value % rightInt.Value,
-		)}
+		if rightInt.Value == 0 {
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
+		}
+
+		return &object.Integer{Value: leftInt.Value % rightInt.Value}
 
 	case "==", "=":
 		return nativeBoolToBooleanObject(leftInt.Value == rightInt.Value)
@@ -357,7 +758,7 @@ func evalIntegerInfixExpression(left object.Object, operator string, right objec
 		return nativeBoolToBooleanObject(leftInt.Value <= rightInt.Value)
 
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -374,7 +775,7 @@ func evalFloatInfixExpression(left object.Object, operator string, right object.
 		return &object.Float{Value: lf.Value * rf.Value}
 	case "/":
 		if rf.Value == 0 {
-			return newError("division error: division by zero")
+			return newErrorOfKind(object.DIVISION_ERROR, "division error: division by zero")
 		}
 
 		return &object.Float{Value: lf.Value / rf.Value}
@@ -398,7 +799,7 @@ func evalFloatInfixExpression(left object.Object, operator string, right object.
 		return nativeBoolToBooleanObject(lf.Value <= rf.Value)
 
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -420,7 +821,7 @@ func evalBooleanInfixExpression(left object.Object, operator string, right objec
 		return nativeBoolToBooleanObject(leftVal && rightVal)
 
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -456,35 +857,37 @@ func evalStringInfixExpression(left object.Object, operator string, right object
 		return evalIntegerInfixExpression(lobj, operator, robj)
 	}
 
-	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	return newErrorOfKind(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 }
 
-func evalIfStatement(node *ast.IfStatement, env *object.Environment) object.Object {
-	condition := Eval(node.Condition, env)
-	if isError(condition) {
-		return condition
+func execIfStatement(node *ast.IfStatement, env *object.Environment) completion {
+	branch, err := chooseBranch(node, env)
+	if err != nil {
+		return completionOf(err)
 	}
 
-	if isTruthy(condition) {
-		return Eval(node.Consequence, env)
+	if branch == nil {
+		return completionOf(NULL)
 	}
 
-	if node.ElseIf != nil {
-		elseIf := evalIfStatement(node.ElseIf, env)
-		if isError(elseIf) {
-			return elseIf
-		}
+	return execBlockStatement(branch, env)
+}
 
-		if elseIf != NULL {
-			return elseIf
+// chooseBranch returns the block an IF statement should execute, or nil if there isn't one. The first branch whose
+// condition is true is chosen, and the rest are skipped no matter what it evaluates to.
+func chooseBranch(node *ast.IfStatement, env *object.Environment) (*ast.BlockStatement, object.Object) {
+	for branch := node; branch != nil; branch = branch.ElseIf {
+		condition := Eval(branch.Condition, env)
+		if isError(condition) {
+			return nil, condition
 		}
-	}
 
-	if node.Else != nil {
-		return Eval(node.Else, env)
+		if isTruthy(condition) {
+			return branch.Consequence, nil
+		}
 	}
 
-	return NULL
+	return node.Else, nil
 }
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
@@ -503,96 +906,447 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return &object.String{Value: text}
 	}
 
-	return newError("identifier not found: " + node.Value)
+	return newErrorOfKind(object.NAME_ERROR, "identifier not found: %s", node.Value)
 }
 
-func evalWhileStatement(node *ast.WhileStatement, env *object.Environment) object.Object {
-	val := Eval(node.Condition, env)
-	if isError(val) {
-		return val
+// evalLoopCondition evaluates the condition of a WHILE or REPEAT loop, which has to be a boolean.
+func evalLoopCondition(condition ast.Expression, loop string, env *object.Environment) (bool, *object.Error) {
+	val := Eval(condition, env)
+	if err, ok := val.(*object.Error); ok {
+		return false, err
 	}
 
 	cond, ok := val.(*object.Boolean)
 	if !ok {
-		return newError("need a boolean for while loop, not %T", val)
+		return false, newError("need a boolean for %s loop, not %s", loop, val.Type())
 	}
 
-	var result object.Object
+	return cond.Value, nil
+}
 
-	for cond.Value {
-		result = Eval(node.Body, env)
-		if isError(result) {
-			return result
-		}
+func execWhileStatement(node *ast.WhileStatement, env *object.Environment) completion {
+	return execLoop(node.Body, env, whileStep(node, env))
+}
 
-		cond, ok = Eval(node.Condition, env).(*object.Boolean)
-		if !ok {
-			return newError("need a boolean for while loop, not %T", cond)
-		}
+// whileStep checks the condition of a WHILE loop before each iteration.
+func whileStep(node *ast.WhileStatement, env *object.Environment) loopStep {
+	return func(first bool) (bool, *object.Error) {
+		return evalLoopCondition(node.Condition, "while", env)
 	}
+}
 
-	return result
+func execForStatement(node *ast.ForStatement, env *object.Environment) completion {
+	step, err := forStep(node, env)
+	if err != nil {
+		return completionOf(err)
+	}
+
+	return execLoop(node.Body, env, step)
 }
 
-func evalForStatement(node *ast.ForStatement, env *object.Environment) object.Object {
-	lower, ok := Eval(node.Lower, env).(*object.Integer)
-	if !ok {
-		return newError("expected integer expression for `for` loop lower bound, got=%T", node.Lower)
+// forStep evaluates the bounds of a FOR loop, and returns a loopStep which sets the loop variable to each value in turn.
+func forStep(node *ast.ForStatement, env *object.Environment) (loopStep, *object.Error) {
+	if isBuiltin(node.Ident.Value) {
+		return nil, newError("cannot assign to builtin: %s", node.Ident.Value)
 	}
 
-	upper, ok := Eval(node.Upper, env).(*object.Integer)
-	if !ok {
-		return newError("expected integer expression for `for` loop upper bounds, got=%T", node.Upper)
+	lower := Eval(node.Lower, env)
+	if err, ok := lower.(*object.Error); ok {
+		return nil, err
 	}
 
-	var val object.Object
+	upper := Eval(node.Upper, env)
+	if err, ok := upper.(*object.Error); ok {
+		return nil, err
+	}
 
-	for i := lower.Value; i <= upper.Value; i++ {
-		if isBuiltin(node.Ident.Value) {
-			return newError("cannot assign to builtin: %s", node.Ident.Value)
-		}
+	var step object.Object = &object.Integer{Value: 1}
+	if lower.Type() == object.FLOAT_OBJ && upper.Type() == object.FLOAT_OBJ {
+		step = &object.Float{Value: 1}
+	}
 
-		err := env.Set(node.Ident.Value, &object.Integer{Value: i})
-		if isError(err) {
-			return err
+	if node.Step != nil {
+		step = Eval(node.Step, env)
+		if err, ok := step.(*object.Error); ok {
+			return nil, err
 		}
+	}
 
-		val = Eval(node.Body, env)
+	// next returns the next value of the loop variable, or nil once the loop is over.
+	var next func() object.Object
 
-		if isError(val) {
-			return val
+	switch {
+	case lower.Type() == object.INTEGER_OBJ && upper.Type() == object.INTEGER_OBJ && step.Type() == object.INTEGER_OBJ:
+		lowerInt, upperInt, stepInt := lower.(*object.Integer), upper.(*object.Integer), step.(*object.Integer)
+
+		if stepInt.Value == 0 {
+			return nil, newError("`for` loop step cannot be zero")
 		}
+
+		// Big integers have a clamped Value, so a loop involving one counts using big.Int instead.
+		if lowerInt.Big != nil || upperInt.Big != nil || stepInt.Big != nil {
+			i, u, s := lowerInt.BigInt(), upperInt.BigInt(), stepInt.BigInt()
+
+			next = func() object.Object {
+				if (s.Sign() > 0 && i.Cmp(u) > 0) || (s.Sign() < 0 && i.Cmp(u) < 0) {
+					return nil
+				}
+
+				current := i
+				i = new(big.Int).Add(i, s)
+
+				return object.NewBigInteger(current)
+			}
+
+			break
+		}
+
+		i, u, s := lowerInt.Value, upperInt.Value, stepInt.Value
+		done := false
+
+		next = func() object.Object {
+			if done || (s > 0 && i > u) || (s < 0 && i < u) {
+				return nil
+			}
+
+			current := i
+
+			// Stop rather than wrapping around when the next value wouldn't fit in an int64, since it would be past the
+			// upper bound anyway.
+			if overflows(i, "+", s) {
+				done = true
+			} else {
+				i += s
+			}
+
+			return &object.Integer{Value: current}
+		}
+
+	case lower.Type() == object.FLOAT_OBJ && upper.Type() == object.FLOAT_OBJ && step.Type() == object.FLOAT_OBJ:
+		l := lower.(*object.Float).Value
+		u := upper.(*object.Float).Value
+		s := step.(*object.Float).Value
+
+		if s == 0 {
+			return nil, newError("`for` loop step cannot be zero")
+		}
+
+		// The loop variable is calculated from the number of iterations rather than by repeated addition so that
+		// rounding errors don't build up. The tolerance stops the last iteration being skipped because of them.
+		tolerance := math.Abs(s) * 1e-9
+		n := 0
+
+		next = func() object.Object {
+			i := l + float64(n)*s
+			if (s > 0 && i > u+tolerance) || (s < 0 && i < u-tolerance) {
+				return nil
+			}
+
+			n++
+
+			return &object.Float{Value: i}
+		}
+
+	case isExact(lower) && isExact(upper) && isExact(step):
+		// At least one of them is a decimal, so any integers are turned into decimals like they are in arithmetic, and
+		// the loop counts exactly using big.Rat.
+		exact := func(obj object.Object) *big.Rat {
+			if i, ok := obj.(*object.Integer); ok {
+				return object.IntegerToDecimal(i).Value
+			}
+
+			return obj.(*object.Decimal).Value
+		}
+
+		i, u, s := new(big.Rat).Set(exact(lower)), exact(upper), exact(step)
+
+		if s.Sign() == 0 {
+			return nil, newError("`for` loop step cannot be zero")
+		}
+
+		next = func() object.Object {
+			if (s.Sign() > 0 && i.Cmp(u) > 0) || (s.Sign() < 0 && i.Cmp(u) < 0) {
+				return nil
+			}
+
+			current := i
+			i = new(big.Rat).Add(i, s)
+
+			return &object.Decimal{Value: current}
+		}
+
+	case isNumeric(lower) && isNumeric(upper) && isNumeric(step):
+		return nil, newError(
+			"mixed types in `for` loop bounds, got lower=%s, upper=%s, step=%s (use all integers, all floats, or integers and decimals)",
+			lower.Type(), upper.Type(), step.Type(),
+		)
+
+	default:
+		return nil, newError(
+			"expected integer, float or decimal expressions for `for` loop bounds, got lower=%s, upper=%s, step=%s",
+			lower.Type(), upper.Type(), step.Type(),
+		)
 	}
 
-	return val
+	return func(first bool) (bool, *object.Error) {
+		current := next()
+		if current == nil {
+			return false, nil
+		}
+
+		if err, ok := env.Set(node.Ident.Value, current).(*object.Error); ok {
+			return false, err
+		}
+
+		return true, nil
+	}, nil
 }
 
-func evalRepeatStatement(node *ast.RepeatStatement, env *object.Environment) object.Object {
-	val := Eval(node.Condition, env)
-	if isError(val) {
-		return val
+func execForInStatement(node *ast.ForInStatement, env *object.Environment) completion {
+	step, err := forInStep(node, env)
+	if err != nil {
+		return completionOf(err)
 	}
 
-	cond, ok := val.(*object.Boolean)
-	if !ok {
-		return newError("need a boolean for while loop, not %T", val)
+	return execLoop(node.Body, env, step)
+}
+
+// forInStep evaluates what a FOR-IN loop iterates over, and returns a loopStep which assigns each item in turn to the
+// variables of the loop. A generator is only asked for each value when the loop is ready for it, and leaving the loop
+// early leaves the generator paused, so the rest of its values can still be used afterwards.
+func forInStep(node *ast.ForInStatement, env *object.Environment) (loopStep, *object.Error) {
+	iterable := Eval(node.Iterable, env)
+	if err, ok := iterable.(*object.Error); ok {
+		return nil, err
+	}
+
+	for _, ident := range node.Idents {
+		if isBuiltin(ident.Value) {
+			return nil, newError("cannot assign to builtin: %s", ident.Value)
+		}
 	}
 
-	var result object.Object
+	var items []object.Object
 
-	for !cond.Value {
-		result = Eval(node.Body, env)
-		if isError(result) {
-			return result
+	// next returns the next item, or false once there are none left.
+	next := func() (object.Object, bool) {
+		if len(items) == 0 {
+			return nil, false
 		}
 
-		cond, ok = Eval(node.Condition, env).(*object.Boolean)
+		item := items[0]
+		items = items[1:]
+
+		return item, true
+	}
+
+	switch iterable := iterable.(type) {
+	case *object.Generator:
+		next = iterable.Resume
+
+	case *object.Array:
+		items = iterable.Elements
+
+	case *object.String:
+		for _, ch := range iterable.Value {
+			items = append(items, &object.String{Value: string(ch)})
+		}
+
+	case *object.Hash:
+		for _, pair := range iterable.SortedPairs() {
+			if len(node.Idents) > 1 {
+				items = append(items, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+			} else {
+				items = append(items, pair.Key)
+			}
+		}
+
+	case *object.Set:
+		items = iterable.SortedElements()
+
+	case *object.EnumType:
+		for _, member := range iterable.Members {
+			items = append(items, member)
+		}
+
+	default:
+		return nil, newError("cannot iterate over %s in `for` loop", iterable.Type())
+	}
+
+	return func(first bool) (bool, *object.Error) {
+		item, ok := next()
 		if !ok {
-			return newError("need a boolean for while loop, not %T", cond)
+			return false, nil
+		}
+
+		if err, ok := item.(*object.Error); ok {
+			return false, err
+		}
+
+		values, err := unpack(item, len(node.Idents))
+		if err != nil {
+			return false, err
 		}
+
+		for i, ident := range node.Idents {
+			if err, ok := env.Set(ident.Value, values[i]).(*object.Error); ok {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}, nil
+}
+
+func evalMultipleAssignment(node *ast.MultipleAssignment, env *object.Environment) object.Object {
+	values := evalExpressions(node.Values, env)
+	if len(values) == 1 && isError(values[0]) {
+		return values[0]
 	}
 
-	return result
+	if len(values) == 1 {
+		unpacked, err := unpack(values[0], len(node.Names))
+		if err != nil {
+			return err
+		}
+
+		values = unpacked
+	}
+
+	if len(values) != len(node.Names) {
+		return newError("assignment mismatch: %d variables but %d values", len(node.Names), len(values))
+	}
+
+	for i, name := range node.Names {
+		if isBuiltin(name.Value) {
+			return newError("cannot assign to builtin: %s", name.Value)
+		}
+
+		err := env.Set(name.Value, values[i])
+		if isError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unpack splits a value into the values of several variables, such as in `q, r <- divmod(17, 5)` or
+// `FOR name, score IN results`. A single variable takes the whole value, and several variables need an array with one
+// element for each of them.
+func unpack(value object.Object, count int) ([]object.Object, *object.Error) {
+	if count == 1 {
+		return []object.Object{value}, nil
+	}
+
+	arr, ok := value.(*object.Array)
+	if !ok {
+		return nil, newErrorOfKind(object.TYPE_ERROR, "cannot unpack %s into %d variables", value.Type(), count)
+	}
+
+	if len(arr.Elements) != count {
+		return nil, newError("cannot unpack %d values into %d variables", len(arr.Elements), count)
+	}
+
+	return arr.Elements, nil
+}
+
+func execRepeatStatement(node *ast.RepeatStatement, env *object.Environment) completion {
+	return execLoop(node.Body, env, repeatStep(node, env))
+}
+
+// repeatStep checks the condition of a REPEAT loop after each iteration. The body always runs at least once, since the
+// condition is only checked at the end.
+func repeatStep(node *ast.RepeatStatement, env *object.Environment) loopStep {
+	return func(first bool) (bool, *object.Error) {
+		if first {
+			return true, nil
+		}
+
+		cond, err := evalLoopCondition(node.Condition, "repeat", env)
+
+		return !cond, err
+	}
+}
+
+func execTryStatement(node *ast.TryStatement, env *object.Environment) completion {
+	result := execBlockStatement(node.Body, env)
+	if result.kind != errorCompletion {
+		return result
+	}
+
+	if err := bindCaughtError(node, result.value.(*object.Error), env); err != nil {
+		return completionOf(err)
+	}
+
+	return execBlockStatement(node.Handler, env)
+}
+
+// bindCaughtError assigns an error caught by a TRY statement to the name given after CATCH, if there is one.
+func bindCaughtError(node *ast.TryStatement, caught *object.Error, env *object.Environment) *object.Error {
+	if node.ErrorName == nil {
+		return nil
+	}
+
+	if isBuiltin(node.ErrorName.Value) {
+		return newError("cannot assign to builtin: %s", node.ErrorName.Value)
+	}
+
+	if err, ok := env.Set(node.ErrorName.Value, errorToRecord(caught)).(*object.Error); ok {
+		return err
+	}
+
+	return nil
+}
+
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	// Throwing an error which was caught earlier raises it again, rather than creating a new error.
+	if record, ok := val.(*object.Record); ok && record.RecordType == object.ErrorRecord {
+		return recordToError(record)
+	}
+
+	err := newErrorOfKind(object.THROWN_ERROR, "%s", val.Inspect())
+	err.Tok = &node.Tok
+
+	return err
+}
+
+// errorToRecord turns an error into a record, so that a program can inspect an error it has caught. The line and
+// column are -1 if the position of the error isn't known.
+func errorToRecord(err *object.Error) *object.Record {
+	line, column := -1, -1
+	if err.Tok != nil {
+		line, column = err.Tok.Line, err.Tok.StartCol
+	}
+
+	return &object.Record{
+		RecordType: object.ErrorRecord,
+		Fields: map[string]object.Object{
+			"message": &object.String{Value: err.Message},
+			"kind":    &object.String{Value: err.ErrorKind()},
+			"line":    &object.Integer{Value: int64(line)},
+			"column":  &object.Integer{Value: int64(column)},
+		},
+	}
+}
+
+// recordToError turns a record created by errorToRecord back into an error.
+func recordToError(record *object.Record) *object.Error {
+	err := &object.Error{Message: record.Fields["message"].Inspect(), Kind: record.Fields["kind"].Inspect()}
+
+	line, lineOk := record.Fields["line"].(*object.Integer)
+	column, columnOk := record.Fields["column"].(*object.Integer)
+
+	if lineOk && columnOk && line.Value >= 0 {
+		err.Tok = &token.Token{Line: int(line.Value), StartCol: int(column.Value), EndCol: int(column.Value)}
+	}
+
+	return err
 }
 
 func evalIndexExpression(left, index object.Object) object.Object {
@@ -610,26 +1364,194 @@ func evalIndexExpression(left, index object.Object) object.Object {
 
 func evalArrayIndexExpression(left, index object.Object) object.Object {
 	array := left.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(array.Elements) - 1)
 
-	if idx < 0 || idx > max {
-		return newError("index out of bounds: %d", idx)
+	idx, err := resolveIndex(index.(*object.Integer), len(array.Elements))
+	if err != nil {
+		return err
 	}
 
 	return array.Elements[idx]
 }
 
 func evalStringIndexExpression(left, index object.Object) object.Object {
-	str := left.(*object.String)
-	idx := index.(*object.Integer).Value
-	max := int64(len(str.Value) - 1)
+	// Strings are indexed by character rather than by byte, like FOR ... IN and LEN.
+	runes := []rune(left.(*object.String).Value)
+
+	idx, err := resolveIndex(index.(*object.Integer), len(runes))
+	if err != nil {
+		return err
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
+// resolveIndex turns an index into an offset from the start of a sequence of the given length. Negative indices count
+// back from the end, so -1 is the last element.
+func resolveIndex(idx *object.Integer, length int) (int64, *object.Error) {
+	resolved := idx.Value
+	if resolved < 0 {
+		resolved += int64(length)
+	}
+
+	// A big integer is always out of bounds, but its Value is clamped so it has to be checked separately.
+	if idx.Big != nil || resolved < 0 || resolved >= int64(length) {
+		return 0, newErrorOfKind(object.INDEX_ERROR, "index out of bounds: %s (length %d)", idx.Inspect(), length)
+	}
+
+	return resolved, nil
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	var (
+		length int
+		runes  []rune
+	)
+
+	switch left := left.(type) {
+	case *object.Array:
+		length = len(left.Elements)
+	case *object.String:
+		runes = []rune(left.Value)
+		length = len(runes)
+	default:
+		return newErrorOfKind(object.TYPE_ERROR, "slice operator not supported: %s", left.Type())
+	}
+
+	bounds := []ast.Expression{node.Start, node.End, node.Step}
+	values := make([]*int64, len(bounds))
+
+	for i, bound := range bounds {
+		if bound == nil {
+			continue
+		}
+
+		val := Eval(bound, env)
+		if isError(val) {
+			return val
+		}
+
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return newErrorOfKind(object.TYPE_ERROR, "slice indices must be INTEGER, got %s", val.Type())
+		}
+
+		values[i] = &integer.Value
+	}
+
+	indices, err := sliceIndices(values[0], values[1], values[2], length)
+	if err != nil {
+		return err
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		elements := make([]object.Object, len(indices))
+		for i, idx := range indices {
+			elements[i] = left.Elements[idx]
+		}
+
+		return &object.Array{Elements: elements}
+
+	default:
+		out := make([]rune, len(indices))
+		for i, idx := range indices {
+			out[i] = runes[idx]
+		}
+
+		return &object.String{Value: string(out)}
+	}
+}
+
+// sliceIndices works out which positions a slice selects from a sequence of the given length. Like Python, bounds that
+// are out of range are clamped rather than being an error, negative bounds count back from the end and a negative step
+// walks the sequence backwards. Nil bounds take their default for the direction of the step.
+func sliceIndices(start, end, step *int64, length int) ([]int, *object.Error) {
+	n := int64(length)
+
+	stride := int64(1)
+	if step != nil {
+		stride = *step
+	}
+
+	if stride == 0 {
+		return nil, newErrorOfKind(object.INDEX_ERROR, "slice step cannot be zero")
+	}
+
+	// The lowest and highest values a bound can take. A backwards slice can stop at -1, just before the first element.
+	lower, upper := int64(0), n
+	if stride < 0 {
+		lower, upper = -1, n-1
+	}
+
+	clamp := func(bound *int64, def int64) int64 {
+		if bound == nil {
+			return def
+		}
+
+		val := *bound
+		if val < 0 {
+			val += n
+		}
+
+		if val < lower {
+			return lower
+		}
+
+		if val > upper {
+			return upper
+		}
+
+		return val
+	}
+
+	var from, to int64
+	if stride > 0 {
+		from, to = clamp(start, lower), clamp(end, upper)
+	} else {
+		from, to = clamp(start, upper), clamp(end, lower)
+	}
 
-	if idx < 0 || idx > max {
-		return newError("index out of bounds: %d", idx)
+	indices := []int{}
+	for i := from; (stride > 0 && i < to) || (stride < 0 && i > to); i += stride {
+		indices = append(indices, int(i))
 	}
 
-	return &object.String{Value: string(str.Value[idx])}
+	return indices, nil
+}
+
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for i, str := range node.Strings {
+		out.WriteString(str)
+
+		if i >= len(node.Expressions) {
+			break
+		}
+
+		val := Eval(node.Expressions[i], env)
+		// An error is given the position of the embedded expression rather than of the whole string, since that is where
+		// it went wrong.
+		if err, ok := val.(*object.Error); ok {
+			if err.Tok == nil {
+				tok := node.Expressions[i].Token()
+				err.Tok = &tok
+			}
+
+			return err
+		}
+
+		if val != nil {
+			out.WriteString(val.Inspect())
+		}
+	}
+
+	return &object.String{Value: out.String()}
 }
 
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
@@ -658,6 +1580,22 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 	return &object.Hash{Pairs: pairs}
 }
 
+func evalSetLiteral(node *ast.SetLiteral, env *object.Environment) object.Object {
+	elements := evalExpressions(node.Elements, env)
+	if len(elements) == 1 && isError(elements[0]) {
+		return elements[0]
+	}
+
+	set := object.NewSet()
+	for _, el := range elements {
+		if !set.Add(el) {
+			return newErrorOfKind(object.TYPE_ERROR, "unusable as set element: %s", el.Type())
+		}
+	}
+
+	return set
+}
+
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
 
@@ -674,7 +1612,32 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
-func evalDotExpression(parent object.Object, child string) object.Object {
+func evalDotExpression(parent object.Object, child string, env *object.Environment) object.Object {
+	switch parent := parent.(type) {
+	case *object.Instance:
+		return evalInstanceMember(parent, parent.Class, child, env)
+	case *object.Super:
+		return evalInstanceMember(parent.Self, parent.Class, child, env)
+	}
+
+	if record, ok := parent.(*object.Record); ok {
+		val, exists := record.Fields[child]
+		if !exists {
+			return newError("record %s has no field %q", record.RecordType.Name, child)
+		}
+
+		return val
+	}
+
+	if enumType, ok := parent.(*object.EnumType); ok {
+		member, exists := enumType.Member(child)
+		if !exists {
+			return newErrorOfKind(object.NAME_ERROR, "enum %s has no member %q", enumType.Name, child)
+		}
+
+		return member
+	}
+
 	module, ok := parent.(*object.Module)
 	if !ok {
 		return newError("cannot use dot operator on %T object", parent)
@@ -692,43 +1655,330 @@ func evalDotExpression(parent object.Object, child string) object.Object {
 	return val
 }
 
-func applySubroutine(sub object.Object, args []object.Object) object.Object {
+func evalEnumDefinition(node *ast.EnumDefinition, env *object.Environment) object.Object {
+	if isBuiltin(node.Name.Value) {
+		return newError("cannot assign to builtin: %s", node.Name.Value)
+	}
+
+	enumType := &object.EnumType{Name: node.Name.Value}
+	for i, ident := range node.Members {
+		if _, exists := enumType.Member(ident.Value); exists {
+			return newError("duplicate member %q in enum %s", ident.Value, node.Name.Value)
+		}
+
+		enumType.Members = append(enumType.Members, &object.EnumMember{EnumType: enumType, Name: ident.Value, Ordinal: i})
+	}
+
+	err := env.SetConstant(node.Name.Value, enumType)
+	if isError(err) {
+		return err
+	}
+
+	return nil
+}
+
+func evalFieldAssignment(node *ast.FieldAssignment, env *object.Environment) object.Object {
+	parent := Eval(node.Object, env)
+	if isError(parent) {
+		return parent
+	}
+
+	if instance, ok := parent.(*object.Instance); ok {
+		return evalInstanceFieldAssignment(instance, node, env)
+	}
+
+	record, ok := parent.(*object.Record)
+	if !ok {
+		return newError("cannot assign to field %q of %s", node.Field.Value, parent.Type())
+	}
+
+	fieldIdx := record.RecordType.FieldIndex(node.Field.Value)
+	if fieldIdx == -1 {
+		return newError("record %s has no field %q", record.RecordType.Name, node.Field.Value)
+	}
+
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	if err := checkFieldType(record.RecordType, fieldIdx, val); err != nil {
+		return err
+	}
+
+	record.Fields[node.Field.Value] = val
+
+	return nil
+}
+
+func evalIndexAssignment(node *ast.IndexAssignment, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	index := Eval(node.Index, env)
+	if isError(index) {
+		return index
+	}
+
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be an INTEGER, got %s", index.Type())
+		}
+
+		resolved, err := resolveIndex(idx, len(left.Elements))
+		if err != nil {
+			return err
+		}
+
+		left.Elements[resolved] = val
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+
+	return nil
+}
+
+func newRecord(recordType *object.RecordType, args []object.Object) object.Object {
+	if len(args) != len(recordType.Fields) {
+		return newError("wrong number of arguments to create %s record. got=%d, want=%d", recordType.Name, len(args), len(recordType.Fields))
+	}
+
+	record := &object.Record{RecordType: recordType, Fields: make(map[string]object.Object)}
+	for i, field := range recordType.Fields {
+		if err := checkFieldType(recordType, i, args[i]); err != nil {
+			return err
+		}
+
+		record.Fields[field] = args[i]
+	}
+
+	return record
+}
+
+// fieldTypes maps the lowercase names of the types used in the AQA specification to the objects they allow.
+var fieldTypes = map[string][]object.Type{
+	"integer":   {object.INTEGER_OBJ},
+	"real":      {object.FLOAT_OBJ, object.INTEGER_OBJ, object.DECIMAL_OBJ},
+	"decimal":   {object.DECIMAL_OBJ, object.INTEGER_OBJ},
+	"boolean":   {object.BOOLEAN_OBJ},
+	"string":    {object.STRING_OBJ},
+	"char":      {object.STRING_OBJ},
+	"character": {object.STRING_OBJ},
+	"array":     {object.ARRAY_OBJ},
+	"map":       {object.HASH_OBJ},
+	"set":       {object.SET_OBJ},
+}
+
+// checkFieldType checks that a value matches the type declared for a field of a record, such as `price : Real`. Any
+// other type name refers to a record, class or enum, so the value has to be a record, instance or enum member of that
+// name. Fields declared without a type can hold anything.
+func checkFieldType(recordType *object.RecordType, fieldIdx int, val object.Object) *object.Error {
+	declared := recordType.FieldTypes[fieldIdx]
+	if declared == "" {
+		return nil
+	}
+
+	if allowed, ok := fieldTypes[strings.ToLower(declared)]; ok {
+		for _, t := range allowed {
+			if val.Type() == t {
+				return nil
+			}
+		}
+	} else {
+		var name string
+
+		switch val := val.(type) {
+		case *object.Record:
+			name = val.RecordType.Name
+		case *object.Instance:
+			for class := val.Class; class != nil; class = class.Parent {
+				if class.Name == declared {
+					return nil
+				}
+			}
+		case *object.EnumMember:
+			name = val.EnumType.Name
+		}
+
+		if name == declared {
+			return nil
+		}
+	}
+
+	return newErrorOfKind(
+		object.TYPE_ERROR, "field %q of record %s must be %s, got %s",
+		recordType.Fields[fieldIdx], recordType.Name, declared, val.Type(),
+	)
+}
+
+// applySubroutine calls a subroutine, FN, method, builtin or record type with the given arguments. The call token is
+// the position of the call in the source, which is used to report problems with the arguments.
+func applySubroutine(sub object.Object, args []object.Object, kwargs []keywordArgument, call token.Token) object.Object {
+	switch sub.(type) {
+	case *object.Subroutine, *object.BoundMethod:
+	default:
+		if len(kwargs) != 0 {
+			return newError("keyword arguments are not supported by %s", sub.Type())
+		}
+	}
+
 	switch sub := sub.(type) {
 	case *object.Subroutine:
-		extended, err := extendSubroutineEnv(sub, args)
+		extended, err := extendSubroutineEnv(sub, args, kwargs, call)
 		if err != nil {
 			return err
 		}
 
-		evaluated := Eval(sub.Body, extended)
-		return unwrapReturnValue(evaluated)
+		if sub.Generator {
+			return newGenerator(subroutineName(sub), sub.Body, extended)
+		}
+
+		// Whether the body finished with a RETURN or by running out of statements, the value it finished with is the
+		// result of the call.
+		return execBlockStatement(sub.Body, extended).value
 
 	case *object.Builtin:
 		return sub.Fn(args...)
 
+	case *object.BoundMethod:
+		return applyMethod(sub, args, kwargs, call)
+
+	case *object.RecordType:
+		return newRecord(sub, args)
+
+	case *object.Class:
+		return newError("use NEW to create an instance of %s", sub.Name)
+
 	default:
 		return newError("not a subroutine, function or builtin: %s", sub.Type())
 	}
 }
 
-func extendSubroutineEnv(sub *object.Subroutine, args []object.Object) (*object.Environment, *object.Error) {
+// extendSubroutineEnv creates the environment a subroutine is executed in, binding each parameter to the argument
+// passed for it by position or by name. Parameters which weren't given an argument take their default value, which is
+// evaluated inside the new environment so that it can refer to the parameters before it. A rest parameter is bound to
+// an array of the positional arguments left over.
+func extendSubroutineEnv(sub *object.Subroutine, args []object.Object, kwargs []keywordArgument, call token.Token) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnvironment(sub.Env)
+	name := subroutineName(sub)
+
+	positional := sub.Parameters
+	hasRest := len(positional) != 0 && positional[len(positional)-1].Rest
+	if hasRest {
+		positional = positional[:len(positional)-1]
+	}
+
+	required := 0
+	for _, param := range positional {
+		if param.Default == nil {
+			required++
+		}
+	}
+
+	values := make([]object.Object, len(sub.Parameters))
+	n := copy(values[:len(positional)], args)
+
+	if hasRest {
+		values[len(positional)] = &object.Array{Elements: args[n:]}
+	}
+
+	for _, kwarg := range kwargs {
+		paramIDx := parameterIndex(positional, kwarg.name)
+		if paramIDx == -1 {
+			return nil, newCallError(call, "unknown keyword argument %q to %s", kwarg.name, name)
+		}
+
+		if values[paramIDx] != nil {
+			return nil, newCallError(call, "duplicate argument %q to %s", kwarg.name, name)
+		}
+
+		values[paramIDx] = kwarg.value
+	}
+
+	got := len(args) + len(kwargs)
+	if got < required || got > len(positional) && !hasRest {
+		return nil, newCallError(
+			call, "wrong number of arguments to %s. got=%d, want=%s", name, got, describeArity(required, len(positional), hasRest),
+		)
+	}
 
 	for paramIDx, param := range sub.Parameters {
-		if isBuiltin(param.Value) {
-			return &object.Environment{}, newError("cannot assign to builtin: %s", param.Value)
+		if isBuiltin(param.Name.Value) {
+			return nil, newError("cannot assign to builtin: %s", param.Name.Value)
 		}
 
-		env.Set(param.Value, args[paramIDx])
+		value := values[paramIDx]
+		if value == nil {
+			if param.Default == nil {
+				return nil, newCallError(call, "missing argument %q to %s", param.Name.Value, name)
+			}
+
+			value = Eval(param.Default, env)
+			if err, ok := value.(*object.Error); ok {
+				return nil, err
+			}
+		}
+
+		env.Set(param.Name.Value, value)
 	}
 
 	return env, nil
 }
 
-func unwrapReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+// describeArity describes the number of arguments a subroutine takes, such as "2", "1 to 3" or "at least 1".
+func describeArity(required, max int, hasRest bool) string {
+	switch {
+	case hasRest:
+		return fmt.Sprintf("at least %d", required)
+	case required == max:
+		return fmt.Sprintf("%d", required)
+	default:
+		return fmt.Sprintf("%d to %d", required, max)
+	}
+}
+
+// newCallError returns an error about the arguments passed to a subroutine, pointing at the call which passed them.
+func newCallError(call token.Token, format string, args ...interface{}) *object.Error {
+	err := newErrorOfKind(object.ARGUMENT_ERROR, format, args...)
+	err.Tok = &call
+
+	return err
+}
+
+// subroutineName returns the name of a subroutine for use in error messages.
+func subroutineName(sub *object.Subroutine) string {
+	if sub.Name == nil {
+		return "FN"
 	}
 
-	return obj
+	return sub.Name.Value
+}
+
+// parameterIndex returns the position of the parameter with the given name, or -1 if there isn't one.
+func parameterIndex(params []*ast.Parameter, name string) int {
+	for i, param := range params {
+		if param.Name.Value == name {
+			return i
+		}
+	}
+
+	return -1
 }
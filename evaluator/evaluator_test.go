@@ -2,7 +2,9 @@ package evaluator
 
 import (
 	"fmt"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/spf13/cast"
 
@@ -45,6 +47,122 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegers(t *testing.T) {
+	factorial := `SUBROUTINE factorial(n)
+	result <- 1
+	FOR i <- 2 TO n
+		result <- result * i
+	ENDFOR
+	RETURN result
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"1 << 64", "18446744073709551616"},
+		{"(1 << 64) >> 63", 2},
+		{"(1 << 64) - (1 << 64) + 5", 5},
+		{"99999999999999999999", "99999999999999999999"},
+		{"99999999999999999999 DIV 10", "9999999999999999999"},
+		{"99999999999999999999 MOD 7", 1},
+		{"-99999999999999999999 MOD 7", -1},
+		{"(1 << 64) / (1 << 62)", 4},
+		{"(1 << 64) / 3", "6148914691236517000"},
+		{"(1 << 64) > 9223372036854775807", true},
+		{"(1 << 64) == 18446744073709551616", true},
+		{"(1 << 64) + 0.5", "18446744073709552000"},
+		{"(1 << 64) / 0", "division error: division by zero"},
+		{"5 DIV 0", "division error: division by zero"},
+		{"5 MOD 0", "division error: division by zero"},
+		{"1 << (1 << 64)", "bit shift too large: 1 << 18446744073709551616"},
+		{factorial + "factorial(25)", "15511210043330985984000000"},
+		{factorial + "factorial(30) DIV factorial(28)", 870},
+		{"m <- MAP {18446744073709551616: \"big\"}\nm[1 << 64]", "big"},
+		{"STRING_TO_INT(\"123456789012345678901234567890\") + 1", "123456789012345678901234567891"},
+		{"INT_TO_STRING(1 << 70)", "1180591620717411303424"},
+		{"SUM([9223372036854775807, 9223372036854775807])", "18446744073709551614"},
+		{"FLOOR(100000000000000000000.5)", "100000000000000000000"},
+		{"count <- 0\nFOR i <- 9223372036854775806 TO 9223372036854775809\ncount <- count + 1\nlast <- i\nENDFOR\nlast", "9223372036854775809"},
+		{"count <- 0\nFOR i <- 9223372036854775806 TO 9223372036854775809\ncount <- count + 1\nENDFOR\ncount", 4},
+		{"count <- 0\nFOR i <- 99999999999999999999 TO 99999999999999999990 STEP -3\ncount <- count + 1\nENDFOR\ncount", 4},
+		{"count <- 0\nFOR i <- 0 TO 10 STEP 1 << 64\ncount <- count + 1\nENDFOR\ncount", 1},
+		{"count <- 0\nFOR i <- 9223372036854775805 TO 9223372036854775807\ncount <- count + 1\nENDFOR\ncount", 3},
+		{"count <- 0\nFOR i <- -9223372036854775807 - 1 TO -9223372036854775807 - 1 STEP -1\ncount <- count + 1\nENDFOR\ncount", 1},
+		{"count <- 0\nFOR i <- 9223372036854775800 TO 9223372036854775807 STEP 5\ncount <- count + 1\nENDFOR\ncount", 2},
+		{"[1, 2, 3][1 << 64]", "index out of bounds: 18446744073709551616 (length 3)"},
+		{"\"abc\"[-(1 << 64)]", "index out of bounds: -18446744073709551616 (length 3)"},
+		{"arr <- [1, 2, 3]\narr[1 << 64] <- 5", "index out of bounds: 18446744073709551616 (length 3)"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0.1d + 0.2d", "0.3"},
+		{"0.1d + 0.2d == 0.3d", true},
+		{"0.1 + 0.2 == 0.3", false},
+		{"19.99d * 3", "59.97"},
+		{"1d / 3", "1/3"},
+		{"1d / 3 * 3", "1"},
+		{"1d / 8", "0.125"},
+		{"-2.5d", "-2.5"},
+		{"10 - 0.01d", "9.99"},
+		{"0.5d + 0.25", "0.75"},
+		{"7.5d DIV 2", 3},
+		{"-7.5d DIV 2", -3},
+		{"7.5d MOD 2", "1.5"},
+		{"-7.5d MOD 2", "-1.5"},
+		{"1.5d > 1", true},
+		{"0.1d < 0.2d", true},
+		{"\"Total: \" + 4.50d", "Total: 4.5"},
+		{"1d / 0", "division error: division by zero"},
+		{"1.5d MOD 0d", "division error: division by zero"},
+		{"m <- MAP {0.5d: \"half\"}\nm[1d / 2]", "half"},
+		{"ROUND(2.5d)", 3},
+		{"ROUND(-2.5d)", -3},
+		{"ROUND(1d / 3, 2)", "0.33"},
+		{"ROUND(2.675d, 2)", "2.68"},
+		{"ROUND(2.5)", 3},
+		{"ROUND(1.23456, 2)", "1.23"},
+		{"ROUND(1.5, 400)", 1.5},
+		{"ROUND(0.1, 20)", 0.1},
+		{"ROUND(123456789012345.5, 3)", 123456789012345.5},
+		{"ROUND(7, 2)", 7},
+		{"ROUND(1.5d, -1)", "number of decimal places in call to `ROUND` must be between 0 and 1000, got=-1"},
+		{"ROUND(1d, 1000000000)", "number of decimal places in call to `ROUND` must be between 0 and 1000, got=1000000000"},
+		{"TRY\nROUND(1d, 1000000000)\nCATCH e\nENDTRY\ne.kind", "ArgumentError"},
+		{"ROUND(1d / 3, 1000) > 0.33d", true},
+		{"m <- MAP {1d: \"x\"}\nm[1]", "x"},
+		{"m <- MAP {2: \"x\"}\nm[2.0d]", "x"},
+		{"m <- MAP {1.5d: \"x\"}\nm[1.50d]", "x"},
+		{"LEN(SET {1, 1d, 1.0d})", 1},
+		{"m <- MAP {(1 << 64): \"big\"}\nm[18446744073709551616d]", "big"},
+		{"FLOOR(-1.5d)", -2},
+		{"CEIL(-1.5d)", -1},
+		{"CEIL(1.25d)", 2},
+		{"STRING_TO_DECIMAL(\"0.1\") * 3", "0.3"},
+		{"STRING_TO_DECIMAL(\"abc\")", "failed to convert \"abc\" to decimal in call to `STRING_TO_DECIMAL`"},
+		{"REAL_TO_DECIMAL(0.1)", "0.1"},
+		{"DECIMAL_TO_REAL(1d / 4)", "0.25"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
 func TestEvalFloatExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -97,6 +215,11 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"true XOR true", false},
 		{"NOT true", false},
 		{"NOT false", true},
+		{"1 < 2 AND 2 < 3", true},
+		{"1 == 2 OR 2 == 2", true},
+		{"NOT 1 == 2", true},
+		{"NOT true AND false", false},
+		{"true OR false AND false", true},
 	}
 
 	for _, tt := range tests {
@@ -105,6 +228,26 @@ func TestEvalBooleanExpression(t *testing.T) {
 	}
 }
 
+func TestShortCircuitEvaluation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"false AND missing", false},
+		{"true OR missing", true},
+		{"true AND missing", "identifier not found: missing"},
+		{"false OR missing", "identifier not found: missing"},
+		{"false XOR missing", "identifier not found: missing"},
+		{"x <- 0\nSUBROUTINE bump()\n x <- x + 1\n RETURN true\nENDSUBROUTINE\nfalse AND bump()\ntrue OR bump()\nx", 0},
+		{"xs <- []\nLEN(xs) > 0 AND xs[0] == 1", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		testExpectedObject(t, evaluated, tt.expected)
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -181,10 +324,7 @@ ENDWHILE
 
 return a`
 
-	evaluated := testEval(t, input)
-	if returnVal, ok := evaluated.(*object.ReturnValue); ok {
-		testIntegerObject(t, returnVal.Value, 10)
-	}
+	testIntegerObject(t, testEval(t, input), 10)
 }
 
 func TestRepeatStatement(t *testing.T) {
@@ -197,10 +337,7 @@ UNTIL a > 10
 
 return a`
 
-	evaluated := testEval(t, input)
-	if returnVal, ok := evaluated.(*object.ReturnValue); ok {
-		testIntegerObject(t, returnVal.Value, 10)
-	}
+	testIntegerObject(t, testEval(t, input), 11)
 }
 
 func TestForStatement(t *testing.T) {
@@ -212,11 +349,320 @@ ENDFOR
 
 return a`
 
-	evaluated := testEval(t, input)
-	if returnVal, ok := evaluated.(*object.ReturnValue); ok {
-		if val, ok := returnVal.Value.(*object.Integer); ok {
-			testIntegerObject(t, val, 55)
-		}
+	testIntegerObject(t, testEval(t, input), 55)
+}
+
+// TestControlFlowSemantics checks how each control-flow construct finishes, such as REPEAT always running its body at
+// least once and only the first matching branch of an IF being run.
+func TestControlFlowSemantics(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// REPEAT checks its condition after the body, not before.
+		{`a <- 0
+REPEAT
+	a <- a + 1
+UNTIL TRUE
+a`, 1},
+		{`a <- 5
+REPEAT
+	a <- a * 2
+UNTIL a > 1
+a`, 10},
+		{`i <- 0
+REPEAT
+	i <- i + 1
+	CONTINUE
+UNTIL i == 3
+i`, 3},
+		{`REPEAT
+UNTIL "yes"`, "need a boolean for repeat loop, not STRING"},
+
+		// WHILE checks its condition before the body.
+		{`a <- 0
+WHILE FALSE
+	a <- a + 1
+ENDWHILE
+a`, 0},
+		{`WHILE 1
+ENDWHILE`, "need a boolean for while loop, not INTEGER"},
+		{`a <- 0
+WHILE a < missing
+	a <- a + 1
+ENDWHILE`, "identifier not found: missing"},
+
+		// Only the first matching branch of an IF runs, even if it evaluates to NULL.
+		{`out <- ""
+x <- 2
+IF x == 1 THEN
+	out <- out + "a"
+ELSE IF x == 2 THEN
+	out <- out + "b"
+	IF FALSE THEN
+		out <- out + "?"
+	ENDIF
+ELSE
+	out <- out + "c"
+ENDIF
+out`, "b"},
+		{`out <- ""
+x <- 3
+IF x == 1 THEN
+	out <- "a"
+ELSE IF x == 2 THEN
+	out <- "b"
+ELSE IF x == 3 THEN
+	out <- "c"
+ELSE
+	out <- "d"
+ENDIF
+out`, "c"},
+		{`x <- 2
+out <- ""
+IF x == 1 THEN
+	out <- "one"
+ELSE IF x == 2 THEN
+	out <- "two"
+ENDIF
+out <- out + "!"
+out`, "two!"},
+		{`IF FALSE THEN
+	1
+ELSE IF missing THEN
+	2
+ENDIF`, "identifier not found: missing"},
+
+		// RETURN leaves every enclosing loop and stops the subroutine.
+		{`SUBROUTINE f()
+	i <- 0
+	REPEAT
+		i <- i + 1
+		IF i == 4 THEN
+			RETURN i * 10
+		ENDIF
+	UNTIL i == 100
+	RETURN -1
+ENDSUBROUTINE
+f()`, 40},
+		{`SUBROUTINE f()
+	FOR i <- 1 TO 3
+		FOR ch IN "abc"
+			IF ch == "b" THEN
+				RETURN ch + i
+			ENDIF
+		ENDFOR
+	ENDFOR
+ENDSUBROUTINE
+f()`, "b1"},
+		{`a <- 1
+RETURN a
+a <- 2`, 1},
+
+		// Errors stop loops straight away.
+		{`count <- 0
+FOR i <- 1 TO 3
+	count <- count + 1
+	x <- missing
+ENDFOR`, "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestForStatementStep(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`out <- ""
+FOR i <- 10 TO 0 STEP -2
+	out <- out + i + " "
+ENDFOR
+out`, "10 8 6 4 2 0 "},
+		{`count <- 0
+FOR i <- 1 TO 10 STEP 3
+	count <- count + 1
+ENDFOR
+count`, 4},
+		{`count <- 0
+FOR i <- 10 TO 1
+	count <- count + 1
+ENDFOR
+count`, 0},
+		{`count <- 0
+FOR x <- 0.0 TO 1.0 STEP 0.1
+	count <- count + 1
+ENDFOR
+count`, 11},
+		{`FOR i <- 1 TO 10 STEP 0
+ENDFOR`, "`for` loop step cannot be zero"},
+		{`all <- []
+FOR i <- 0 TO 2 STEP 0.5d
+	all <- APPEND(all, i)
+ENDFOR
+all`, "[0, 0.5, 1, 1.5, 2]"},
+		{`total <- 0d
+FOR x <- 0.1d TO 1d STEP 0.1d
+	total <- total + x
+ENDFOR
+total`, "5.5"},
+		{`count <- 0
+FOR i <- 1d TO 0 STEP -0.25d
+	count <- count + 1
+ENDFOR
+count`, 5},
+		{`FOR i <- 0d TO 1 STEP 0d
+ENDFOR`, "`for` loop step cannot be zero"},
+		{`FOR i <- 0d TO 1.0
+ENDFOR`, "mixed types in `for` loop bounds, got lower=DECIMAL, upper=FLOAT, step=INTEGER (use all integers, all floats, or integers and decimals)"},
+		{`FOR i <- 0 TO 1 STEP 0.5
+ENDFOR`, "mixed types in `for` loop bounds, got lower=INTEGER, upper=INTEGER, step=FLOAT (use all integers, all floats, or integers and decimals)"},
+		{`FOR i <- "a" TO 10
+ENDFOR`, "expected integer, float or decimal expressions for `for` loop bounds, got lower=STRING, upper=INTEGER, step=INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestBreakAndContinue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`i <- 0
+WHILE TRUE
+	i <- i + 1
+	IF i == 5 THEN
+		BREAK
+	ENDIF
+ENDWHILE
+i`, 5},
+		{`total <- 0
+FOR i <- 1 TO 10
+	IF i MOD 2 == 0 THEN
+		CONTINUE
+	ENDIF
+	total <- total + i
+ENDFOR
+total`, 25},
+		{`total <- 0
+FOR i <- 1.0 TO 10.0
+	IF i > 3.0 THEN
+		BREAK
+	ENDIF
+	total <- total + 1
+ENDFOR
+total`, 3},
+		{`out <- ""
+FOR ch IN "a-b-c"
+	IF ch == "-" THEN
+		CONTINUE
+	ENDIF
+	out <- out + ch
+ENDFOR
+out`, "abc"},
+		{`i <- 0
+REPEAT
+	i <- i + 1
+	IF i == 3 THEN
+		BREAK
+	ENDIF
+UNTIL i == 10
+i`, 3},
+		{`count <- 0
+FOR i <- 1 TO 3
+	FOR j <- 1 TO 3
+		IF j == 2 THEN
+			BREAK
+		ENDIF
+		count <- count + 1
+	ENDFOR
+ENDFOR
+count`, 3},
+		{`SUBROUTINE find(arr, target)
+	FOR i <- 0 TO LEN(arr) - 1
+		IF arr[i] == target THEN
+			RETURN i
+		ENDIF
+	ENDFOR
+	RETURN -1
+ENDSUBROUTINE
+find([4, 5, 6], 5)`, 1},
+		{`SUBROUTINE first_even(arr)
+	i <- 0
+	WHILE i < LEN(arr)
+		IF arr[i] MOD 2 == 0 THEN
+			RETURN arr[i]
+		ENDIF
+		i <- i + 1
+	ENDWHILE
+	RETURN -1
+ENDSUBROUTINE
+first_even([3, 5, 8, 10])`, 8},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`total <- 0
+FOR n IN [1, 2, 3, 4]
+	total <- total + n
+ENDFOR
+total`, 10},
+		{`out <- ""
+FOR ch IN "abc"
+	out <- ch + out
+ENDFOR
+out`, "cba"},
+		{`out <- ""
+FOR key IN {"b": 2, "a": 1, "c": 3}
+	out <- out + key
+ENDFOR
+out`, "abc"},
+		{`keys <- []
+FOR key IN {10: "ten", 9: "nine", 100: "hundred"}
+	keys <- APPEND(keys, key)
+ENDFOR
+keys`, "[9, 10, 100]"},
+		{`total <- 0
+FOR key, value IN {"a": 1, "b": 2}
+	total <- total + value
+ENDFOR
+total`, 3},
+		{`count <- 0
+FOR item IN []
+	count <- count + 1
+ENDFOR
+count`, 0},
+		{`FOR item IN 5
+ENDFOR`, "cannot iterate over INTEGER in `for` loop"},
+		{`FOR a, b IN [1, 2]
+ENDFOR`, "cannot unpack INTEGER into 2 variables"},
+		{`total <- 0
+FOR name, score IN [["a", 1], ["b", 2]]
+	total <- total + score
+ENDFOR
+total`, 3},
+		{`FOR a, b IN [[1, 2], [3]]
+ENDFOR`, "cannot unpack 1 values into 2 variables"},
+		{`FOR a, b, c IN {"a": 1}
+ENDFOR`, "cannot unpack 2 values into 3 variables"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
 	}
 }
 
@@ -246,11 +692,7 @@ func TestReturnStatements(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(t, tt.input)
-
-		if returnVal, ok := evaluated.(*object.ReturnValue); ok {
-			testIntegerObject(t, returnVal.Value, tt.expected)
-		}
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
 	}
 
 }
@@ -376,6 +818,801 @@ ENDSUBROUTINE
 	}
 }
 
+func TestSubroutineArity(t *testing.T) {
+	add := `SUBROUTINE add(x, y)
+	RETURN x + y
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{add + `add(1)`, "wrong number of arguments to add. got=1, want=2"},
+		{add + `add(1, 2, 3)`, "wrong number of arguments to add. got=3, want=2"},
+		{add + `total <- 1 + add()`, "wrong number of arguments to add. got=0, want=2"},
+		{add + `add(y <- 1, z <- 2)`, "unknown keyword argument \"z\" to add"},
+		{`SUBROUTINE f(a <- 1, b)
+	RETURN a + b
+ENDSUBROUTINE
+f(5)`, "missing argument \"b\" to f"},
+		{`apply <- FN(x) { x }
+apply()`, "wrong number of arguments to FN. got=0, want=1"},
+		{`SUBROUTINE broken()
+	RETURN 1 + "a" - 1
+ENDSUBROUTINE
+broken()`, "type mismatch: STRING - INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestCallErrorPositions(t *testing.T) {
+	add := `SUBROUTINE add(x, y)
+	RETURN x + y
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{add + `add(1)`, "ERROR: wrong number of arguments to add. got=1, want=2 (line=3, col=0)"},
+		{add + `total <- 1 + add()`, "ERROR: wrong number of arguments to add. got=0, want=2 (line=3, col=13)"},
+		{add + `add(y <- 1, z <- 2)`, "ERROR: unknown keyword argument \"z\" to add (line=3, col=0)"},
+		{`apply <- FN(x) { x }
+apply()`, "ERROR: wrong number of arguments to FN. got=0, want=1 (line=1, col=0)"},
+		{"CLASS Point\nx <- 0\nENDCLASS\nNEW Point(1)", "ERROR: wrong number of arguments to NEW Point. got=1, want=0 (line=3, col=0)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if err.Inspect() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, err.Inspect())
+		}
+	}
+}
+
+func TestDefaultAndKeywordArguments(t *testing.T) {
+	greet := `SUBROUTINE greet(name, greeting <- "Hello")
+	RETURN greeting + " " + name
+ENDSUBROUTINE
+`
+
+	area := `SUBROUTINE area(width, height <- width)
+	RETURN width * height
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{greet + `greet("Sam")`, "Hello Sam"},
+		{greet + `greet("Sam", "Hi")`, "Hi Sam"},
+		{greet + `greet("Sam", greeting <- "Hi")`, "Hi Sam"},
+		{greet + `greet(greeting <- "Hi", name <- "Sam")`, "Hi Sam"},
+		{area + `area(3)`, 9},
+		{area + `area(3, 4)`, 12},
+		{area + `area(height <- 2, width <- 5)`, 10},
+		{`apply <- FN(x, y <- 10) { x + y }
+apply(1)`, 11},
+		{greet + `greet()`, "wrong number of arguments to greet. got=0, want=1 to 2"},
+		{greet + `greet("Sam", "Hi", "!")`, "wrong number of arguments to greet. got=3, want=1 to 2"},
+		{greet + `greet("Sam", colour <- "red")`, "unknown keyword argument \"colour\" to greet"},
+		{greet + `greet("Sam", name <- "Bob")`, "duplicate argument \"name\" to greet"},
+		{greet + `greet(greeting <- "Hi", greeting <- "Hey", name <- "Sam")`, "duplicate argument \"greeting\" to greet"},
+		{greet + `greet(greeting <- "Hi", "Sam")`, "positional argument \"Sam\" follows keyword argument"},
+		{`LEN(x <- "abc")`, "keyword arguments are not supported by BUILTIN"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestRestParametersAndSpread(t *testing.T) {
+	total := `SUBROUTINE total(first, ...rest)
+	result <- first
+	FOR n IN rest
+		result <- result + n
+	ENDFOR
+	RETURN result
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{total + `total(1)`, 1},
+		{total + `total(1, 2, 3)`, 6},
+		{total + `scores <- [2, 3, 4]
+total(...scores)`, 9},
+		{total + `total(1, ...[2, 3], 4)`, 10},
+		{`SUBROUTINE rest(...values)
+	RETURN values
+ENDSUBROUTINE
+rest()`, "[]"},
+		{`apply <- FN(f, ...args) { f(...args) }
+apply(FN(a, b) { a - b }, 10, 4)`, 6},
+		{total + `total()`, "wrong number of arguments to total. got=0, want=at least 1"},
+		{total + `total(1, rest <- [2])`, "unknown keyword argument \"rest\" to total"},
+		{total + `total(...5)`, "cannot spread INTEGER, need an ARRAY"},
+		{`SUBROUTINE pair(a, b)
+	RETURN a + b
+ENDSUBROUTINE
+pair(...[1, 2, 3])`, "wrong number of arguments to pair. got=3, want=2"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`TRY
+	x <- 1 + "a" - 1
+CATCH e
+	result <- e.message
+ENDTRY
+result`, "type mismatch: STRING - INTEGER"},
+		{`TRY
+	x <- 1 + "a" - 1
+CATCH e
+ENDTRY
+e`, "Error(message: type mismatch: STRING - INTEGER, kind: TypeError, line: 1, column: 14)"},
+		{`SUBROUTINE add(x, y)
+	RETURN x + y
+ENDSUBROUTINE
+TRY
+	total <- add(1)
+CATCH e
+ENDTRY
+e`, "Error(message: wrong number of arguments to add. got=1, want=2, kind: ArgumentError, line: 4, column: 10)"},
+		{`TRY
+	x <- missing
+CATCH e
+ENDTRY
+e.kind`, "NameError"},
+		{`TRY
+	x <- 10 / 0
+CATCH e
+ENDTRY
+e.kind`, "DivisionError"},
+		{`TRY
+	x <- [1, 2][5]
+CATCH e
+ENDTRY
+e.kind`, "IndexError"},
+		{`result <- "no error"
+TRY
+	x <- 1
+CATCH
+	result <- "caught"
+ENDTRY
+result`, "no error"},
+		{`SUBROUTINE check(age)
+	IF age < 0 THEN
+		THROW "age must be positive"
+	ENDIF
+	RETURN age
+ENDSUBROUTINE
+TRY
+	check(-1)
+CATCH e
+ENDTRY
+e`, "Error(message: age must be positive, kind: Error, line: 2, column: 2)"},
+		{`THROW "age must be positive"`, "age must be positive"},
+		{`THROW 5 + 5`, "10"},
+		{`TRY
+	TRY
+		THROW "inner"
+	CATCH e
+		THROW e
+	ENDTRY
+CATCH f
+ENDTRY
+f.message + " " + f.line`, "inner 2"},
+		{`TRY
+	x <- 1
+CATCH e
+ENDTRY
+y <- 1
+TRY
+	THROW "first"
+CATCH e
+	THROW "second"
+ENDTRY`, "second"},
+		{`SUBROUTINE f()
+	TRY
+		RETURN 1
+	CATCH
+		RETURN 2
+	ENDTRY
+	RETURN 3
+ENDSUBROUTINE
+f()`, 1},
+		{`count <- 0
+WHILE true
+	TRY
+		count <- count + 1
+		IF count == 3 THEN
+			BREAK
+		ENDIF
+	CATCH
+	ENDTRY
+ENDWHILE
+count`, 3},
+		{`total <- 0
+FOR s IN ["1", "x", "3"]
+	TRY
+		total <- total + STRING_TO_INT(s)
+	CATCH
+		CONTINUE
+	ENDTRY
+ENDFOR
+total`, 4},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestMultipleAssignment(t *testing.T) {
+	divmod := `SUBROUTINE divmod(x, y)
+	RETURN x DIV y, x MOD y
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`a, b <- 1, 2
+a * 10 + b`, 12},
+		{`a, b <- 1, 2
+a, b <- b, a
+a * 10 + b`, 21},
+		{divmod + `q, r <- divmod(17, 5)
+q * 10 + r`, 32},
+		{divmod + `divmod(17, 5)`, "[3, 2]"},
+		{`x, y, z <- [1, 2, 3]
+x + y + z`, 6},
+		{`a, b <- 1, 2, 3`, "assignment mismatch: 2 variables but 3 values"},
+		{`a, b <- [1, 2, 3]`, "cannot unpack 3 values into 2 variables"},
+		{`a, b <- 5`, "cannot unpack INTEGER into 2 variables"},
+		{`a, b <- 1, missing`, "identifier not found: missing"},
+		{`CONSTANT c <- 1
+a, c <- 1, 2`, "cannot assign to constant c"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestConditionalExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`x <- 5
+IF x > 0 THEN "pos" ELSE "neg"`, "pos"},
+		{`x <- -5
+sign <- IF x > 0 THEN "pos" ELSE IF x == 0 THEN "zero" ELSE "neg"
+sign`, "neg"},
+		{`IF true THEN 1 ELSE missing`, 1},
+		{`IF false THEN missing ELSE 2`, 2},
+		{`abs <- FN(x) { RETURN IF x < 0 THEN -x ELSE x }
+abs(-3) + abs(4)`, 7},
+		{`10 + (IF 1 > 2 THEN 1 ELSE 2)`, 12},
+		{`IF missing THEN 1 ELSE 2`, "identifier not found: missing"},
+		{`sign <- FN(x) { IF x > 0 THEN "pos" ELSE "neg" }
+sign(1) + sign(-1)`, "posneg"},
+		{`sign <- FN(x) { IF x > 0 THEN "pos" ELSE IF x == 0 THEN "zero" ELSE "neg" }
+sign(0)`, "zero"},
+		{`IF true THEN 1 ELSE 2
+5`, 5},
+		{`IF 1 > 2 THEN 10 ELSE 20 ENDIF`, 20},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`score <- 7
+total <- 10
+f"Score: {score}/{total}"`, "Score: 7/10"},
+		{`f"{1 + 2} is {"three"}"`, "3 is three"},
+		{`f"{[1, 2]} and {MAP {"a": 1}["a"]}"`, "[1, 2] and 1"},
+		{`f"{1.5 * 2} {TRUE}"`, "3 true"},
+		{`f"{{braces}} {f"nested {1}"}"`, "{braces} nested 1"},
+		{`f"no expressions"`, "no expressions"},
+		{`f"a
+{missing}"`, "identifier not found: missing"},
+		{`TRY
+	s <- f"a {missing}"
+CATCH e
+	s <- f"{e.kind} at {e.line}:{e.column}"
+ENDTRY
+s`, "NameError at 1:11"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"arr <- [1, 2, 3]\narr[1] <- 10\narr", "[1, 10, 3]"},
+		{"arr <- [1, 2, 3]\narr[1 + 1] <- arr[0] * 5\narr[2]", 5},
+		{"grid <- [[0, 0], [0, 0]]\ngrid[1][0] <- 7\ngrid", "[[0, 0], [7, 0]]"},
+		{"scores <- MAP {\"bob\": 1}\nscores[\"bob\"] <- 7\nscores[\"bob\"]", 7},
+		{"scores <- MAP {}\nscores[\"alice\"] <- 3\nscores[\"alice\"]", 3},
+		{"m <- MAP {\"a\": [1, 2]}\nm[\"a\"][0] <- 5\nm[\"a\"]", "[5, 2]"},
+		{"arr <- [1, 2, 3]\narr[3] <- 10", "index out of bounds: 3 (length 3)"},
+		{"arr <- [1, 2, 3]\narr[-1] <- 10\narr", "[1, 2, 10]"},
+		{"arr <- [1, 2, 3]\narr[-4] <- 10", "index out of bounds: -4 (length 3)"},
+		{"arr <- [1, 2, 3]\narr[\"a\"] <- 10", "array index must be an INTEGER, got STRING"},
+		{"s <- \"abc\"\ns[0] <- \"z\"", "index assignment not supported: STRING"},
+		{"m <- MAP {}\nm[[1]] <- 2", "unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestFunctionLiterals(t *testing.T) {
+	mapfn := `SUBROUTINE mapfn(arr, f)
+	newArr <- []
+	FOR i <- 0 TO LEN(arr)-1
+		newArr <- APPEND(newArr, f(arr[i]))
+	ENDFOR
+	RETURN newArr
+ENDSUBROUTINE
+`
+
+	adder := `SUBROUTINE adder(n)
+	RETURN FN(x) { x + n }
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"FN(x) { x * 2 }(4)", 8},
+		{"double <- FN(x) { x * 2 }\ndouble(21)", 42},
+		{"FN() { 5 }()", 5},
+		{"FN(x) {\n  y <- x + 1\n  RETURN y * 2\n}(2)", 6},
+		{adder + "add_3 <- adder(3)\nadd_3(4)", 7},
+		{mapfn + "mapfn([1,2,3], FN(x) { x + 5 })", "[6, 7, 8]"},
+		{"LEN(FN(s) { s + \"!\" }(\"hi\"))", 3},
+		{"FN(x, y) { x - y }", "<fn(x, y)>"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	car := `RECORD Car
+	make : String
+	price : Integer
+ENDRECORD
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{car + `c <- Car("Tesla", 100)
+c.make`, "Tesla"},
+		{car + `c <- Car("Tesla", 100)
+c.price <- c.price - 10
+c.price`, 90},
+		{car + `c <- Car("Tesla", 100)
+other <- c
+other.make <- "Ford"
+c.make`, "Ford"},
+		{car + `Car("Tesla", 100)`, "Car(make: Tesla, price: 100)"},
+		{car + `Car("Tesla")`, "wrong number of arguments to create Car record. got=1, want=2"},
+		{car + `c <- Car("Tesla", 100)
+c.colour`, "record Car has no field \"colour\""},
+		{car + `c <- Car("Tesla", 100)
+c.colour <- "red"`, "record Car has no field \"colour\""},
+		{`a <- 5
+a.b <- 10`, "cannot assign to field \"b\" of INTEGER"},
+		{`RECORD Point
+	x
+	x
+ENDRECORD`, "duplicate field \"x\" in record Point"},
+		{car + `Car(100, "Tesla")`, "field \"make\" of record Car must be String, got INTEGER"},
+		{car + `c <- Car("Tesla", 100)
+c.price <- "free"`, "field \"price\" of record Car must be Integer, got STRING"},
+		{car + `RECORD Owner
+	name : String
+	car : Car
+	height : Real
+ENDRECORD
+Owner("Sam", Car("Tesla", 100), 2)`, "Owner(name: Sam, car: Car(make: Tesla, price: 100), height: 2)"},
+		{car + `RECORD Owner
+	car : Car
+ENDRECORD
+Owner("Tesla")`, "field \"car\" of record Owner must be Car, got STRING"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestEnums(t *testing.T) {
+	colour := `ENUM Colour
+	RED, GREEN
+	BLUE
+ENDENUM
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{colour + "Colour.RED", "Colour.RED"},
+		{colour + "Colour", "<enum Colour(RED, GREEN, BLUE)>"},
+		{colour + "Colour.RED == Colour.RED", true},
+		{colour + "Colour.RED == Colour.GREEN", false},
+		{colour + "Colour.RED != Colour.BLUE", true},
+		{colour + "Colour.RED < Colour.BLUE", true},
+		{colour + "Colour.BLUE <= Colour.GREEN", false},
+		{colour + "c <- Colour.GREEN\nIF c = Colour.GREEN THEN\n\"go\"\nELSE\n\"stop\"\nENDIF", "go"},
+		{colour + "Colour.RED == 0", false},
+		{colour + "Colour.PURPLE", "enum Colour has no member \"PURPLE\""},
+		{colour + "names <- MAP {Colour.RED: \"red\", Colour.BLUE: \"blue\"}\nnames[Colour.BLUE]", "blue"},
+		{colour + "SET {Colour.RED, Colour.RED, Colour.GREEN}", "SET {Colour.GREEN, Colour.RED}"},
+		{colour + "all <- []\nFOR c IN Colour\nall <- APPEND(all, c)\nENDFOR\nall", "[Colour.RED, Colour.GREEN, Colour.BLUE]"},
+		{colour + "LEN(Colour)", 3},
+		{colour + "Colour.RED IN Colour", true},
+		{colour + "\"RED\" IN Colour", false},
+		{colour + "Colour <- 5", "cannot assign to constant Colour"},
+		{colour + "ENUM Size\nRED\nENDENUM\nColour.RED == Size.RED", false},
+		{colour + "ENUM Size\nRED\nENDENUM\nColour.RED < Size.RED", "cannot compare members of different enums: Colour.RED < Size.RED"},
+		{"ENUM Suit\nHEARTS, HEARTS\nENDENUM", "duplicate member \"HEARTS\" in enum Suit"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestClasses(t *testing.T) {
+	animal := `CLASS Animal
+	PRIVATE name
+	PUBLIC sound <- "..."
+
+	PUBLIC SUBROUTINE NEW(name)
+		self.name <- name
+	ENDSUBROUTINE
+
+	SUBROUTINE getName()
+		RETURN self.name
+	ENDSUBROUTINE
+
+	SUBROUTINE speak()
+		RETURN self.name + " says " + self.sound
+	ENDSUBROUTINE
+
+	PRIVATE SUBROUTINE secret()
+		RETURN "hidden"
+	ENDSUBROUTINE
+
+	SUBROUTINE namer()
+		RETURN FN() { self.name }
+	ENDSUBROUTINE
+ENDCLASS
+`
+
+	dog := animal + `CLASS Dog INHERITS Animal
+	tricks <- 0
+
+	SUBROUTINE NEW(name, tricks)
+		SUPER.NEW(name)
+		self.tricks <- tricks
+		self.sound <- "woof"
+	ENDSUBROUTINE
+
+	SUBROUTINE speak()
+		RETURN SUPER.speak() + "!"
+	ENDSUBROUTINE
+
+	SUBROUTINE reveal()
+		RETURN self.secret()
+	ENDSUBROUTINE
+ENDCLASS
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{animal + "Animal", "<class Animal>"},
+		{animal + "a <- NEW Animal(\"Cat\")\na", "Animal(name: Cat, sound: ...)"},
+		{animal + "a <- NEW Animal(\"Cat\")\na.speak()", "Cat says ..."},
+		{animal + "a <- NEW Animal(\"Cat\")\na.getName()", "Cat"},
+		{animal + "a <- NEW Animal(\"Cat\")\na.sound <- \"meow\"\na.speak()", "Cat says meow"},
+		{animal + "a <- NEW Animal(\"Cat\")\na.speak", "<method Animal.speak>"},
+		{animal + "a <- NEW Animal(\"Cat\")\nspeak <- a.speak\nspeak()", "Cat says ..."},
+		{animal + "a <- NEW Animal(name <- \"Cat\")\na.getName()", "Cat"},
+		{animal + "a <- NEW Animal(\"Cat\")\na.name", "field \"name\" of class Animal is private"},
+		{animal + "a <- NEW Animal(\"Cat\")\na.name <- \"Dog\"", "field \"name\" of class Animal is private"},
+		{animal + "a <- NEW Animal(\"Cat\")\na.secret()", "method \"secret\" of class Animal is private"},
+		{animal + "a <- NEW Animal(\"Cat\")\nf <- a.namer()\nf()", "Cat"},
+		{animal + "a <- NEW Animal(\"Cat\")\nself <- a\na.name", "field \"name\" of class Animal is private"},
+		{animal + "a <- NEW Animal(\"Cat\")\nself <- a\na.name <- \"Dog\"", "field \"name\" of class Animal is private"},
+		{animal + "SUBROUTINE peek(self)\nRETURN self.name\nENDSUBROUTINE\npeek(NEW Animal(\"Cat\"))", "field \"name\" of class Animal is private"},
+		{animal + "CLASS Vet\nSUBROUTINE examine(a)\nRETURN a.name\nENDSUBROUTINE\nENDCLASS\nv <- NEW Vet()\nv.examine(NEW Animal(\"Cat\"))", "field \"name\" of class Animal is private"},
+		{animal + "a <- NEW Animal(\"Cat\")\na.colour", "class Animal has no member \"colour\""},
+		{animal + "a <- NEW Animal(\"Cat\")\na.colour <- \"black\"", "class Animal has no field \"colour\""},
+		{animal + "a <- NEW Animal(\"Cat\")\na.speak <- 5", "cannot assign to method \"speak\" of class Animal"},
+		{animal + "NEW Animal()", "wrong number of arguments to NEW. got=0, want=1"},
+		{animal + "Animal(\"Cat\")", "use NEW to create an instance of Animal"},
+		{animal + "a <- NEW Animal(\"Cat\")\na == a", true},
+		{animal + "a <- NEW Animal(\"Cat\")\nb <- NEW Animal(\"Cat\")\na == b", false},
+		{animal + "a <- NEW Animal(\"Cat\")\nb <- a\nb.sound <- \"meow\"\na.sound", "meow"},
+		{animal + "Animal <- 5", "cannot assign to constant Animal"},
+		{dog + "Dog", "<class Dog(Animal)>"},
+		{dog + "d <- NEW Dog(\"Rex\", 3)\nd", "Dog(name: Rex, sound: woof, tricks: 3)"},
+		{dog + "d <- NEW Dog(\"Rex\", 3)\nd.speak()", "Rex says woof!"},
+		{dog + "d <- NEW Dog(\"Rex\", 3)\nd.getName()", "Rex"},
+		{dog + "d <- NEW Dog(\"Rex\", 3)\nd.reveal()", "hidden"},
+		{dog + "d <- NEW Dog(\"Rex\", 3)\nd.tricks", 3},
+		{"CLASS Point\nx <- 0\ny <- 0\nENDCLASS\np <- NEW Point()\np.x <- 3\np", "Point(x: 3, y: 0)"},
+		{"CLASS Point\nx <- 0\nENDCLASS\nNEW Point(1)", "wrong number of arguments to NEW Point. got=1, want=0"},
+		{"CLASS Counter\ncount <- 0\nSUBROUTINE increment()\nself.count <- self.count + 1\nRETURN self\nENDSUBROUTINE\nENDCLASS\nc <- NEW Counter()\nc.increment().increment().count", 2},
+		{"SUBROUTINE f()\nRETURN SUPER\nENDSUBROUTINE\nf()", "SUPER used outside a method of a class which inherits from another"},
+		{"x <- 5\nCLASS A INHERITS x\nENDCLASS", "class A cannot inherit from INTEGER"},
+		{"CLASS A\nx\nSUBROUTINE x()\nENDSUBROUTINE\nENDCLASS", "duplicate member \"x\" in class A"},
+		{"NEW 5()", "cannot use NEW on INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	count := `SUBROUTINE count(n)
+	FOR i <- 1 TO n
+		YIELD i
+	ENDFOR
+ENDSUBROUTINE
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{count + "count(3)", "<generator count>"},
+		{count + "total <- 0\nFOR x IN count(4)\ntotal <- total + x\nENDFOR\ntotal", 10},
+		{count + "g <- count(2)\nNEXT(g)", 1},
+		{count + "g <- count(2)\nNEXT(g)\nNEXT(g)", 2},
+		{count + "g <- count(2)\nNEXT(g)\nNEXT(g)\nNEXT(g)", "generator count has no more values"},
+		{count + "g <- count(0)\nNEXT(g, \"done\")", "done"},
+		{count + "g <- count(5)\nFOR x IN g\nIF x = 2 THEN\nBREAK\nENDIF\nENDFOR\nNEXT(g)", 3},
+		{count + "g <- count(3)\nall <- []\nFOR x IN g\nall <- APPEND(all, x)\nENDFOR\nFOR x IN g\nall <- APPEND(all, x)\nENDFOR\nall", "[1, 2, 3]"},
+		{"FOR a, b IN FN() { YIELD 1, 2 }()\nENDFOR\na + b", 3},
+		{"SUBROUTINE f()\nYIELD 1\nRETURN 5\nYIELD 2\nENDSUBROUTINE\ng <- f()\nNEXT(g)\nNEXT(g, 0)", 0},
+		{"SUBROUTINE f()\nYIELD 1\nYIELD 1 / 0\nENDSUBROUTINE\nFOR x IN f()\nENDFOR", "division error: division by zero"},
+		{"SUBROUTINE f()\nYIELD NEXT(g)\nENDSUBROUTINE\ng <- f()\nNEXT(g)", "generator f is already running"},
+		{"calls <- 0\nSUBROUTINE f()\ncalls <- calls + 1\nYIELD calls\nENDSUBROUTINE\ng <- f()\ncalls", 0},
+		{"SUBROUTINE fib()\na, b <- 0, 1\nWHILE TRUE\nYIELD a\na, b <- b, a + b\nENDWHILE\nENDSUBROUTINE\ng <- fib()\nFOR i <- 1 TO 10\nNEXT(g)\nENDFOR\nNEXT(g)", 55},
+		{"CLASS Range\nn <- 3\nSUBROUTINE items()\nFOR i <- 1 TO self.n\nYIELD i\nENDFOR\nENDSUBROUTINE\nENDCLASS\nr <- NEW Range()\ntotal <- 0\nFOR x IN r.items()\ntotal <- total + x\nENDFOR\ntotal", 6},
+		{"NEXT(5)", "argument to `NEXT` not supported, got=INTEGER"},
+		{"SUBROUTINE f()\nFOR i <- 1 TO 4\nIF i MOD 2 = 0 THEN\nYIELD \"even\"\nELSE\nYIELD \"odd\"\nENDIF\nENDFOR\nENDSUBROUTINE\nall <- []\nFOR x IN f()\nall <- APPEND(all, x)\nENDFOR\nall", "[odd, even, odd, even]"},
+		{"SUBROUTINE f()\nTRY\nYIELD 1\nYIELD 1 / 0\nCATCH e\nYIELD e.kind\nENDTRY\nYIELD 3\nENDSUBROUTINE\nall <- []\nFOR x IN f()\nall <- APPEND(all, x)\nENDFOR\nall", "[1, DivisionError, 3]"},
+		{"SUBROUTINE f()\nFOR i <- 1 TO 3\nFOR j <- 1 TO 3\nIF j = i THEN\nCONTINUE\nENDIF\nIF j > 2 THEN\nBREAK\nENDIF\nYIELD i * 10 + j\nENDFOR\nENDFOR\nENDSUBROUTINE\nall <- []\nFOR x IN f()\nall <- APPEND(all, x)\nENDFOR\nall", "[12, 21, 31, 32]"},
+		{"SUBROUTINE f()\ni <- 0\nREPEAT\ni <- i + 1\nIF i = 3 THEN\nRETURN 0\nENDIF\nYIELD i\nUNTIL FALSE\nENDSUBROUTINE\nall <- []\nFOR x IN f()\nall <- APPEND(all, x)\nENDFOR\nall", "[1, 2]"},
+		{count + "SUBROUTINE doubled(g)\nFOR x IN g\nYIELD x * 2\nENDFOR\nENDSUBROUTINE\nSUBROUTINE f()\nFOR x IN doubled(count(3))\nYIELD x\nENDFOR\nENDSUBROUTINE\nall <- []\nFOR x IN f()\nall <- APPEND(all, x)\nENDFOR\nall", "[2, 4, 6]"},
+		{"SUBROUTINE f()\nFOR i <- 1 TO \"a\"\nYIELD i\nENDFOR\nENDSUBROUTINE\nNEXT(f())", "expected integer, float or decimal expressions for `for` loop bounds, got lower=INTEGER, upper=STRING, step=INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestAbandonedGeneratorsAreStopped(t *testing.T) {
+	tests := []string{
+		`SUBROUTINE naturals()
+	i <- 0
+	WHILE TRUE
+		YIELD i
+		i <- i + 1
+	ENDWHILE
+ENDSUBROUTINE
+
+SUBROUTINE first()
+	g <- naturals()
+	RETURN NEXT(g)
+ENDSUBROUTINE
+
+FOR i <- 1 TO 50
+	first()
+ENDFOR`,
+		`SUBROUTINE first()
+	SUBROUTINE naturals()
+		i <- 0
+		WHILE TRUE
+			YIELD i
+			i <- i + 1
+		ENDWHILE
+	ENDSUBROUTINE
+
+	g <- naturals()
+	RETURN NEXT(g)
+ENDSUBROUTINE
+
+FOR i <- 1 TO 50
+	first()
+ENDFOR`,
+		`SUBROUTINE first()
+	naturals <- FN() {
+		i <- 0
+		REPEAT
+			YIELD i
+			i <- i + 1
+		UNTIL FALSE
+	}
+
+	g <- naturals()
+	RETURN NEXT(g)
+ENDSUBROUTINE
+
+FOR i <- 1 TO 50
+	first()
+ENDFOR`,
+	}
+
+	for _, input := range tests {
+		before := runtime.NumGoroutine()
+		testEval(t, input)
+
+		for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if after := runtime.NumGoroutine(); after > before {
+			t.Errorf("abandoned generators were not stopped. goroutines before=%d, after=%d, input=%q", before, after, input)
+		}
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `## Returns the area of a circle.
+SUBROUTINE area(r)
+	RETURN PI * r * r
+ENDSUBROUTINE
+
+## An approximation of pi.
+CONSTANT PI <- 3.14
+
+SUBROUTINE undocumented()
+	RETURN 1
+ENDSUBROUTINE
+
+CLASS Dog
+	## Makes a noise.
+	SUBROUTINE speak()
+		RETURN "woof"
+	ENDSUBROUTINE
+ENDCLASS`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.Parse()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	env := object.NewEnvironment()
+	Eval(program, env)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"area", "Returns the area of a circle."},
+		{"PI", "An approximation of pi."},
+		{"Dog.speak", "Makes a noise."},
+	}
+
+	for _, tt := range tests {
+		doc, ok := env.Doc(tt.name)
+		if !ok {
+			t.Errorf("%s has no documentation", tt.name)
+			continue
+		}
+
+		if doc != tt.expected {
+			t.Errorf("documentation for %s is wrong. got=%q, want=%q", tt.name, doc, tt.expected)
+		}
+	}
+
+	if doc, ok := env.Doc("undocumented"); ok {
+		t.Errorf("undocumented should have no documentation. got=%q", doc)
+	}
+}
+
+func TestRedefinitionForgetsDocComments(t *testing.T) {
+	input := `## Says hello.
+SUBROUTINE greet()
+	RETURN "hello"
+ENDSUBROUTINE
+
+SUBROUTINE greet()
+	RETURN "hi"
+ENDSUBROUTINE
+
+CLASS Dog
+	## Makes a noise.
+	SUBROUTINE speak()
+		RETURN "woof"
+	ENDSUBROUTINE
+ENDCLASS
+
+CLASS Dog
+	SUBROUTINE speak()
+		RETURN "yap"
+	ENDSUBROUTINE
+ENDCLASS`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.Parse()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	env := object.NewEnvironment()
+	Eval(program, env)
+
+	for _, name := range []string{"greet", "Dog.speak"} {
+		if doc, ok := env.Doc(name); ok {
+			t.Errorf("%s should have no documentation after being redefined. got=%q", name, doc)
+		}
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 
@@ -414,14 +1651,19 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`LEN("")`, 0},
 		{`LEN("four")`, 4},
 		{`LEN("hello world!")`, 12},
+		{`LEN("héllo")`, 5},
 		{`LEN(1)`, "argument to `LEN` not supported, got=INTEGER"},
 		{`LEN("one", "two")`, "wrong number of arguments. got=2, want=1"},
 
 		{`POSITION("computer science", "m")`, 2},
+		{`POSITION("café au lait", "a")`, 1},
+		{`POSITION("naïve", "v")`, 3},
 		{`POSITION("comp")`, "wrong number of arguments. got=1, want=2"},
 		{`POSITION(5, "ten")`, "argument to `POSITION` not supported, got=INTEGER"},
 
 		{`SUBSTRING(2, 9, "computer science")`, "mputer s"},
+		{`SUBSTRING(1, 3, "héllo")`, "éll"},
+		{`SUBSTRING(0, 5, "hello")`, "invalid bounds [0:5] in call to SUBSTRING"},
 		{`SUBSTRING("oops", "oops", 5)`, "argument 1 to `SUBSTRING` not supported, got=STRING"},
 		{`SUBSTRING(2, "oops", 5)`, "argument 2 to `SUBSTRING` not supported, got=STRING"},
 		{`SUBSTRING(2, 3, 5)`, "argument 3 to `SUBSTRING` not supported, got=INTEGER"},
@@ -448,6 +1690,8 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`RANDOM_INT(1)`, "wrong number of arguments. got=1, want=2"},
 		{`RANDOM_INT('a', 2)`, "argument 1 to `RANDOM_INT` not supported, got=STRING"},
 		{`RANDOM_INT(2, 'a')`, "argument 2 to `RANDOM_INT` not supported, got=STRING"},
+		{`RANDOM_INT(5, 5)`, 5},
+		{`RANDOM_INT(3, 1)`, "upper bound in call to `RANDOM_INT` is less than the lower bound, got lower=3, upper=1"},
 	}
 
 	for _, tt := range tests {
@@ -471,6 +1715,34 @@ func TestBuiltinFunctions(t *testing.T) {
 	}
 }
 
+func TestRandomIntBigBounds(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`low <- 1 << 70
+ok <- TRUE
+FOR i <- 1 TO 50
+	r <- RANDOM_INT(low, low + 2)
+	ok <- ok AND r >= low AND r <= low + 2
+ENDFOR
+ok`, true},
+		{`b <- 1 << 100
+ok <- TRUE
+FOR i <- 1 TO 50
+	r <- RANDOM_INT(-b, b)
+	ok <- ok AND r >= -b AND r <= b
+ENDFOR
+ok`, true},
+		{`b <- 1 << 100
+RANDOM_INT(b, -b)`, "upper bound in call to `RANDOM_INT` is less than the lower bound, got lower=1267650600228229401496703205376, upper=-1267650600228229401496703205376"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
 func TestArrayLiteral(t *testing.T) {
 	input := `[1, 2 * 2, 3 + 3]`
 
@@ -534,6 +1806,124 @@ func TestArrayIndexExpressions(t *testing.T) {
 
 }
 
+func TestNegativeIndexing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][-4]", "index out of bounds: -4 (length 3)"},
+		{"\"hello\"[-1]", "o"},
+		{"\"hello\"[-5]", "h"},
+		{"\"hello\"[-6]", "index out of bounds: -6 (length 5)"},
+		{"\"héllo\"[1]", "é"},
+		{"\"héllo\"[-4]", "é"},
+		{"\"日本語\"[2]", "語"},
+		{"\"日本語\"[3]", "index out of bounds: 3 (length 3)"},
+		{"[][-1]", "index out of bounds: -1 (length 0)"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4, 5][1:4]", "[2, 3, 4]"},
+		{"[1, 2, 3, 4, 5][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][3:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:]", "[1, 2, 3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:-2]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4, 5][::2]", "[1, 3, 5]"},
+		{"[1, 2, 3, 4, 5][::-1]", "[5, 4, 3, 2, 1]"},
+		{"[1, 2, 3, 4, 5][3:0:-1]", "[4, 3, 2]"},
+		{"[1, 2, 3, 4, 5][-1:-4:-2]", "[5, 3]"},
+		{"[1, 2, 3, 4, 5][2:100]", "[3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][-100:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][4:1]", "[]"},
+		{"[][:]", "[]"},
+		{"\"hello\"[1:4]", "ell"},
+		{"\"hello\"[:3]", "hel"},
+		{"\"hello\"[::-1]", "olleh"},
+		{"\"hello\"[10:]", ""},
+		{"\"héllo\"[0:2]", "hé"},
+		{"\"héllo\"[::-1]", "olléh"},
+		{"\"日本語\"[-2:]", "本語"},
+		{"arr <- [1, 2, 3]\ncopy <- arr[:]\ncopy[0] <- 10\narr", "[1, 2, 3]"},
+		{"[1, 2, 3][::0]", "slice step cannot be zero"},
+		{"[1, 2, 3][\"a\":]", "slice indices must be INTEGER, got STRING"},
+		{"5[1:2]", "slice operator not supported: INTEGER"},
+		{"[1, 2, 3][missing:]", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestSets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"SET {3, 1, 2, 1}", "SET {1, 2, 3}"},
+		{"SET {}", "SET {}"},
+		{"SET {10, 2, 1.5, \"b\", \"a\", true}", "SET {true, 1.5, 2, 10, a, b}"},
+		{"LEN(SET {1, 1, 2})", 2},
+		{"2 IN SET {1, 2}", true},
+		{"3 IN SET {1, 2}", false},
+		{"[1] IN SET {1, 2}", false},
+		{"SET {1, 2} | SET {2, 3}", "SET {1, 2, 3}"},
+		{"SET {1, 2} & SET {2, 3}", "SET {2}"},
+		{"SET {1, 2} - SET {2, 3}", "SET {1}"},
+		{"SET {1, 2} == SET {2, 1}", true},
+		{"SET {1, 2} != SET {1}", true},
+		{"SET {1, 2} * SET {1}", "unknown operator: SET * SET"},
+		{"SET {[1]}", "unusable as set element: ARRAY"},
+		{"SET {missing}", "identifier not found: missing"},
+		{"UNION(SET {1}, SET {2})", "SET {1, 2}"},
+		{"INTERSECTION(SET {1, 2}, SET {2})", "SET {2}"},
+		{"DIFFERENCE(SET {1, 2}, SET {2})", "SET {1}"},
+		{"UNION(SET {1}, [2])", "argument 2 to `UNION` not supported, got=ARRAY"},
+		{"s <- SET {1}\nSET_ADD(s, 2, 3)\ns", "SET {1, 2, 3}"},
+		{"s <- SET {1, 2}\nSET_REMOVE(s, 2, 5)\ns", "SET {1}"},
+		{"ARRAY_TO_SET([3, 1, 3])", "SET {1, 3}"},
+		{"SET_TO_ARRAY(SET {3, 1})", "[1, 3]"},
+		{"total <- 0\nFOR n IN SET {1, 2, 3}\ntotal <- total + n\nENDFOR\ntotal", 6},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestInOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"2 IN [1, 2, 3]", true},
+		{"4 IN [1, 2, 3]", false},
+		{"\"a\" IN MAP {\"a\": 1}", true},
+		{"\"b\" IN MAP {\"a\": 1}", false},
+		{"\"ell\" IN \"hello\"", true},
+		{"\"z\" IN \"hello\"", false},
+		{"1 IN \"hello\"", "type mismatch: INTEGER IN STRING"},
+		{"1 IN 2", "unknown operator: INTEGER IN INTEGER"},
+		{"NOT 4 IN [1, 2, 3]", true},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `two <- "two"
 	MAP {                                
@@ -697,6 +2087,46 @@ func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 	return true
 }
 
+// testExpectedObject checks an object against an expected value. Integers are compared as integers, and strings are
+// compared against the message of an error, the value of a string or the Inspect() output of anything else.
+func testExpectedObject(t *testing.T, obj object.Object, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, obj, int64(expected))
+	case bool:
+		return testBooleanObject(t, obj, expected)
+	case float64:
+		return testFloatObject(t, obj, expected)
+	case nil:
+		return testNullObject(t, obj)
+	case string:
+		switch obj := obj.(type) {
+		case *object.Error:
+			if obj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, obj.Message)
+				return false
+			}
+		case *object.String:
+			if obj.Value != expected {
+				t.Errorf("wrong string value. expected=%q, got=%q", expected, obj.Value)
+				return false
+			}
+		case nil:
+			t.Errorf("object is nil, expected %q", expected)
+			return false
+		default:
+			if obj.Inspect() != expected {
+				t.Errorf("wrong value for %T. expected=%q, got=%q", obj, expected, obj.Inspect())
+				return false
+			}
+		}
+	default:
+		t.Fatalf("testExpectedObject: unhandled expected type %T", expected)
+	}
+
+	return true
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
 	_, ok := obj.(*object.Null)
 	if !ok {
@@ -0,0 +1,339 @@
+package evaluator
+
+import (
+	"github.com/ollybritton/aqa/ast"
+	"github.com/ollybritton/aqa/object"
+)
+
+// generatorRun is the body of a generator, part of the way through running. Rather than running the body on its own
+// goroutine, the statements which are part of the way through are kept as a stack of frames, with the innermost one
+// on top. A paused generator is then just an ordinary value, so one which is abandoned is garbage collected like any
+// other, no matter what its body can reach.
+type generatorRun struct {
+	frames []generatorFrame
+	child  *completion // The completion of the frame which finished last, to be passed to the frame below it.
+
+	yielded bool
+	value   object.Object
+
+	yields map[ast.Statement]bool // Remembers which statements contain a YIELD, since loops check them repeatedly.
+}
+
+// generatorFrame is a statement which contains a YIELD, part of the way through running. Only statements containing a
+// YIELD need a frame, since the rest can run to completion using execStatement without the generator pausing.
+type generatorFrame interface {
+	// step carries on running the statement. child is the completion of the frame it pushed last, or nil if it hasn't
+	// pushed one since it last ran. It has to push a new frame, yield a value or finish before returning.
+	step(r *generatorRun, child *completion)
+}
+
+func (r *generatorRun) push(frame generatorFrame) {
+	r.frames = append(r.frames, frame)
+}
+
+func (r *generatorRun) yield(val object.Object) {
+	r.yielded, r.value = true, val
+}
+
+func (r *generatorRun) finish(result completion) {
+	r.frames[len(r.frames)-1] = nil
+	r.frames = r.frames[:len(r.frames)-1]
+	r.child = &result
+}
+
+// resume runs the body until it yields a value. It returns false if the body finishes instead, in which case r.child
+// holds the completion of the whole body.
+func (r *generatorRun) resume() (object.Object, bool) {
+	r.yielded, r.value = false, nil
+
+	for len(r.frames) > 0 {
+		child := r.child
+		r.child = nil
+
+		r.frames[len(r.frames)-1].step(r, child)
+		if r.yielded {
+			return r.value, true
+		}
+	}
+
+	return nil, false
+}
+
+// containsYield reports whether a statement contains a YIELD belonging to this generator. Bodies of subroutines and FNs
+// defined inside the generator are expressions or definitions rather than blocks, so they aren't looked inside.
+func (r *generatorRun) containsYield(stmt ast.Statement) bool {
+	if found, ok := r.yields[stmt]; ok {
+		return found
+	}
+
+	found := false
+
+	// block checks a block which might be missing, such as the ELSE of an IF statement.
+	block := func(b *ast.BlockStatement) bool {
+		return b != nil && r.containsYield(b)
+	}
+
+	switch stmt := stmt.(type) {
+	case *ast.YieldStatement:
+		found = true
+
+	case *ast.BlockStatement:
+		for _, s := range stmt.Statements {
+			if r.containsYield(s) {
+				found = true
+				break
+			}
+		}
+
+	case *ast.IfStatement:
+		for branch := stmt; branch != nil && !found; branch = branch.ElseIf {
+			found = block(branch.Consequence) || block(branch.Else)
+		}
+
+	case *ast.WhileStatement:
+		found = block(stmt.Body)
+
+	case *ast.ForStatement:
+		found = block(stmt.Body)
+
+	case *ast.ForInStatement:
+		found = block(stmt.Body)
+
+	case *ast.RepeatStatement:
+		found = block(stmt.Body)
+
+	case *ast.TryStatement:
+		found = block(stmt.Body) || block(stmt.Handler)
+	}
+
+	r.yields[stmt] = found
+
+	return found
+}
+
+// frameFor creates the frame which runs a statement containing a YIELD. Loops evaluate their bounds or what they iterate
+// over straight away, which can fail.
+func (r *generatorRun) frameFor(stmt ast.Statement, env *object.Environment) (generatorFrame, *object.Error) {
+	switch stmt := stmt.(type) {
+	case *ast.BlockStatement:
+		return &blockFrame{block: stmt, env: env}, nil
+
+	case *ast.IfStatement:
+		return &ifFrame{node: stmt, env: env}, nil
+
+	case *ast.WhileStatement:
+		return &loopFrame{body: stmt.Body, env: env, next: whileStep(stmt, env)}, nil
+
+	case *ast.ForStatement:
+		next, err := forStep(stmt, env)
+		if err != nil {
+			return nil, err
+		}
+
+		return &loopFrame{body: stmt.Body, env: env, next: next}, nil
+
+	case *ast.ForInStatement:
+		next, err := forInStep(stmt, env)
+		if err != nil {
+			return nil, err
+		}
+
+		return &loopFrame{body: stmt.Body, env: env, next: next}, nil
+
+	case *ast.RepeatStatement:
+		return &loopFrame{body: stmt.Body, env: env, next: repeatStep(stmt, env)}, nil
+
+	case *ast.TryStatement:
+		return &tryFrame{node: stmt, env: env}, nil
+	}
+
+	return nil, newError("cannot pause a generator inside %T", stmt)
+}
+
+// blockFrame runs the statements of a block one at a time, in the same way as execBlockStatement. It is the only frame
+// which yields values, since a YIELD is always a statement inside a block.
+type blockFrame struct {
+	block  *ast.BlockStatement
+	env    *object.Environment
+	next   int        // The index of the statement to run next.
+	result completion // The completion of the last statement which finished.
+}
+
+func (f *blockFrame) step(r *generatorRun, child *completion) {
+	if child != nil && f.complete(*child) {
+		r.finish(f.result)
+		return
+	}
+
+	for f.next < len(f.block.Statements) {
+		stmt := f.block.Statements[f.next]
+
+		if yield, ok := stmt.(*ast.YieldStatement); ok {
+			val := Eval(yield.Value, f.env)
+			if isError(val) {
+				f.complete(completionOf(val))
+				r.finish(f.result)
+				return
+			}
+
+			f.complete(completion{kind: normalCompletion})
+			r.yield(val)
+			return
+		}
+
+		if r.containsYield(stmt) {
+			frame, err := r.frameFor(stmt, f.env)
+			if err != nil {
+				f.complete(completionOf(err))
+				r.finish(f.result)
+				return
+			}
+
+			r.push(frame)
+			return
+		}
+
+		if f.complete(execStatement(stmt, f.env)) {
+			r.finish(f.result)
+			return
+		}
+	}
+
+	r.finish(f.result)
+}
+
+// complete records how the current statement finished and moves on to the next one. It reports whether the statement
+// transferred control, so that the rest of the block should be skipped.
+func (f *blockFrame) complete(result completion) bool {
+	f.result = positionError(result, f.block.Statements[f.next])
+	f.next++
+
+	return f.result.kind != normalCompletion
+}
+
+// ifFrame runs the branch of an IF statement chosen by chooseBranch.
+type ifFrame struct {
+	node *ast.IfStatement
+	env  *object.Environment
+}
+
+func (f *ifFrame) step(r *generatorRun, child *completion) {
+	if child != nil {
+		r.finish(*child)
+		return
+	}
+
+	branch, err := chooseBranch(f.node, f.env)
+	if err != nil {
+		r.finish(completionOf(err))
+		return
+	}
+
+	if branch == nil {
+		r.finish(completionOf(NULL))
+		return
+	}
+
+	r.push(&blockFrame{block: branch, env: f.env})
+}
+
+// loopFrame runs any kind of loop, in the same way as execLoop.
+type loopFrame struct {
+	body   *ast.BlockStatement
+	env    *object.Environment
+	next   loopStep
+	result completion
+}
+
+func (f *loopFrame) step(r *generatorRun, child *completion) {
+	if child != nil {
+		var stop bool
+		f.result, stop = loopCompletion(*child)
+		if stop {
+			r.finish(f.result)
+			return
+		}
+	}
+
+	more, err := f.next(child == nil)
+	if err != nil {
+		r.finish(completionOf(err))
+		return
+	}
+
+	if !more {
+		r.finish(f.result)
+		return
+	}
+
+	r.push(&blockFrame{block: f.body, env: f.env})
+}
+
+// tryFrame runs a TRY statement, in the same way as execTryStatement.
+type tryFrame struct {
+	node     *ast.TryStatement
+	env      *object.Environment
+	catching bool
+}
+
+func (f *tryFrame) step(r *generatorRun, child *completion) {
+	if child == nil {
+		r.push(&blockFrame{block: f.node.Body, env: f.env})
+		return
+	}
+
+	if f.catching || child.kind != errorCompletion {
+		r.finish(*child)
+		return
+	}
+
+	f.catching = true
+
+	if err := bindCaughtError(f.node, child.value.(*object.Error), f.env); err != nil {
+		r.finish(completionOf(err))
+		return
+	}
+
+	r.push(&blockFrame{block: f.node.Handler, env: f.env})
+}
+
+// newGenerator creates a generator which runs the body of a subroutine inside env, the environment its arguments have
+// already been bound in. The body isn't started until the first value is asked for.
+func newGenerator(name string, body *ast.BlockStatement, env *object.Environment) *object.Generator {
+	run := &generatorRun{
+		frames: []generatorFrame{&blockFrame{block: body, env: env}},
+		yields: make(map[ast.Statement]bool),
+	}
+
+	var running, finished bool
+
+	resume := func() (object.Object, bool) {
+		if finished {
+			return nil, false
+		}
+
+		if running {
+			return newError("generator %s is already running", name), true
+		}
+
+		running = true
+		val, ok := run.resume()
+		running = false
+
+		if ok {
+			return val, true
+		}
+
+		finished = true
+
+		// The value of a RETURN inside a generator is ignored, but an error is passed on to whoever asked for the next
+		// value.
+		if run.child.kind == errorCompletion {
+			return run.child.value, true
+		}
+
+		return nil, false
+	}
+
+	return &object.Generator{Name: name, Resume: resume}
+}
@@ -41,6 +41,10 @@ func newError(message string, args ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(message, args...)}
 }
 
+func newErrorOfKind(kind string, message string, args ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(message, args...), Kind: kind}
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR_OBJ
@@ -49,6 +53,15 @@ func isError(obj object.Object) bool {
 	return false
 }
 
+func isNumeric(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ || obj.Type() == object.DECIMAL_OBJ
+}
+
+// isExact reports whether a number is stored exactly, which means it is an integer or a decimal.
+func isExact(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.DECIMAL_OBJ
+}
+
 func isBuiltin(name string) bool {
 	if _, ok := builtins.Builtins[strings.ToUpper(name)]; ok {
 		return true
@@ -1,27 +1,53 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/ollybritton/aqa/token"
 )
 
+// unicodeOperators maps the special characters used in the AQA specification to the token.Type of their ASCII
+// equivalent. The literal of the resulting token is normalised to the ASCII form, so later stages only need to handle
+// one spelling of each operator.
+var unicodeOperators = map[rune]token.Type{
+	'←': token.ASSIGN,
+	'≥': token.GT_EQ,
+	'≤': token.LT_EQ,
+	'≠': token.NOT_EQ,
+	'×': token.ASTERISK,
+	'÷': token.SLASH,
+}
+
 // Lexer is a lexer for an AQA++ program.
 // Its job is to translate a series of characters into chunks such as INTEGER(5) or TRUE. It also attaches information such
 // as the position inside the input.
 //
-// BUG(me): At the moment, the lexer does not support Unicode, so only ASCII characters are supported.
+// The input is read rune-by-rune, so positions inside the input are byte offsets but column numbers count characters.
 type Lexer struct {
 	input string
 
-	position     int // Index to the current char the lexer is using.
-	readPosition int // Index to the next char to be read.
+	position     int // Byte index to the current char the lexer is using.
+	readPosition int // Byte index to the next char to be read.
 
 	curLinePosition int // The position that token is located at, relative to the current line
 	curLine         int // The line the current token is located on.
 
 	startPosition int // The start position of the current token.
 
-	ch byte // Current char under examination.
+	ch rune // Current char under examination.
+
+	// interpolations holds the interpolated strings whose embedded expressions are currently being lexed, innermost
+	// last. It is used to tell whether a `}` closes an embedded expression.
+	interpolations []*interpolation
+}
 
+// interpolation records an interpolated string whose embedded expression is being lexed.
+type interpolation struct {
+	quote rune // The quote character the string started with.
+	depth int  // The number of braces opened inside the embedded expression which haven't been closed yet.
 }
 
 // New returns a new, initialised lexer.
@@ -37,25 +63,28 @@ func New(input string) *Lexer {
 // readChar reads the next character in the input. If there are no characters left to read (i.e the input is finished or the
 // input is blank), then the l.ch value is set to the NUL character.
 func (l *Lexer) readChar() {
+	width := 1
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 		l.curLinePosition++
 	}
 
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += width
 }
 
-// peekChar returns the next char in the input as a byte.
+// peekChar returns the next char in the input as a rune.
 // Like readChar, it returns the NUL character if there is no more input.
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
 
-	return l.input[l.readPosition]
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return ch
 }
 
 // skipWhitespace will skip over whitespace. If it encounters a newline, it increments the startLine and resets the startPosition.
@@ -66,28 +95,77 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-// skipComment will skip over a comment.
+// skipComment will skip over a comment, up to and including the newline which ends it.
 func (l *Lexer) skipComment() {
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
 
-	if l.ch != '#' {
+	l.readChar()
 
-		return
-	}
+	l.curLine++
+	l.curLinePosition = 0
+}
+
+// skipBlockComment will skip over a block comment such as #[ ... ]#, which can span multiple lines. If the comment is
+// never closed, it returns a token.ERROR token and false.
+func (l *Lexer) skipBlockComment() (token.Token, bool) {
+	line, col := l.curLine, l.curLinePosition
+	l.readChar() // The '[' after the '#'.
 
-	for l.ch != '\n' && l.ch != byte(0) {
+	for {
 		l.readChar()
 
+		switch {
+		case l.ch == 0:
+			problem := fmt.Sprintf("unterminated block comment (line=%d, col=%d)", line, col)
+			return token.NewToken(token.ERROR, problem, line, col, l.curLinePosition), false
+
+		case l.ch == ']' && l.peekChar() == '#':
+			l.readChar()
+			l.readChar()
+
+			return token.Token{}, true
+
+		case l.ch == '\n':
+			l.curLine++
+			l.curLinePosition = -1
+		}
 	}
+}
 
-	l.readChar()
+// isDocComment returns true if the lexer is at the start of a documentation comment. Documentation comments start with
+// exactly two hashes and must be on a line of their own, so "###" banners and comments after code are ignored.
+func (l *Lexer) isDocComment() bool {
+	if !strings.HasPrefix(l.input[l.position:], "##") || strings.HasPrefix(l.input[l.position:], "###") {
+		return false
+	}
 
-	l.curLine++
-	l.curLinePosition = 0
+	lineStart := strings.LastIndexByte(l.input[:l.position], '\n') + 1
+	return strings.TrimLeft(l.input[lineStart:l.position], " \t\r") == ""
+}
+
+// readDocComment reads a documentation comment, starting on its first '#'. Like other comments, the newline which
+// ends it is skipped.
+func (l *Lexer) readDocComment() token.Token {
+	l.startPosition = l.curLinePosition
+	line := l.curLine
+
+	l.readChar()
+	l.readChar()
 
-	if l.ch == '#' {
-		l.skipComment()
+	start := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
 	}
 
+	text := strings.TrimPrefix(l.input[start:l.position], " ")
+	text = strings.TrimRight(text, " \t\r")
+
+	tok := token.NewToken(token.DOC_COMMENT, text, line, l.startPosition, l.curLinePosition-1)
+	l.skipComment()
+
+	return tok
 }
 
 // readIdentifier will reads a set of characters (including an underscore) and returns the string representation of that
@@ -133,6 +211,12 @@ func (l *Lexer) readNumber() (string, token.Type) {
 		}
 	}
 
+	// A trailing `d` makes the number a decimal, such as `0.1d`, which is stored exactly rather than as a float.
+	if (numtype == "integer" || numtype == "float") && (l.ch == 'd' || l.ch == 'D') && !isValidIdentCharacter(l.peekChar()) && !isDigit(l.peekChar()) {
+		l.readChar()
+		return l.input[start:l.position], token.DECIMAL
+	}
+
 	switch numtype {
 	case "integer", "hexidecimal", "binary":
 		return l.input[start:l.position], token.INT
@@ -142,36 +226,155 @@ func (l *Lexer) readNumber() (string, token.Type) {
 
 }
 
-// readString will read a string. A string is a set of characters surrounded by either a `'` or `"`
-func (l *Lexer) readString(start byte) string {
-	if start != '"' && start != '\'' {
-		panic("lexer: invalid char for start string: " + string(start))
-	}
-	l.startPosition = l.curLinePosition
-
-	l.readChar()
-	literal := ""
+// escapes maps the character following a backslash inside a string to the character it represents.
+var escapes = map[rune]string{
+	'n':  "\n",
+	't':  "\t",
+	'r':  "\r",
+	'\\': "\\",
+	'"':  "\"",
+	'\'': "'",
+}
 
+// readStringContents reads the characters of a string up to its closing quote, starting on the character before them.
+// Strings may span multiple lines. Escape sequences such as `\n` are replaced with the character they represent unless
+// the string is raw, and unknown escape sequences are left as they are.
+// If the string is interpolated, reading also stops at a `{` which starts an embedded expression, and `{{` and `}}` are
+// read as single braces.
+// The returned rune is the character reading stopped on, which is 0 if the input ended first. If the string contains an
+// invalid escape, problem describes it.
+func (l *Lexer) readStringContents(quote rune, raw, interpolated bool) (contents string, end rune, problem string) {
 	for {
+		l.readChar()
+
 		switch {
-		case l.ch == start:
-			return literal
+		case l.ch == quote || l.ch == 0:
+			return contents, l.ch, problem
 
-		case l.ch == '\\' && l.peekChar() == start:
-			l.readChar()
-			literal += string(start)
+		case interpolated && (l.ch == '{' || l.ch == '}') && l.peekChar() == l.ch:
+			contents += string(l.ch)
 			l.readChar()
 
-		case l.ch == '\\':
-			l.readChar()
+		case interpolated && l.ch == '{':
+			return contents, l.ch, problem
+
+		case l.ch == '\n':
+			contents += "\n"
+
+			l.curLine++
+			l.curLinePosition = -1
+
+		case l.ch == '\\' && !raw:
+			if escaped, ok := escapes[l.peekChar()]; ok {
+				contents += escaped
+				l.readChar()
+				continue
+			}
+
+			if l.peekChar() == 'u' {
+				l.readChar()
+
+				r, ok := l.readUnicodeEscape()
+				if !ok {
+					problem = "invalid unicode escape in string, expected \\u followed by 4 hexadecimal digits"
+				}
+
+				contents += string(r)
+				continue
+			}
+
+			contents += "\\"
 
 		default:
-			literal += string(l.ch)
-			l.readChar()
+			contents += string(l.ch)
 		}
 	}
 }
 
+// readUnicodeEscape reads the 4 hexadecimal digits of a `\uXXXX` escape, starting on the `u`. It stops early if it finds
+// something other than a hexadecimal digit.
+func (l *Lexer) readUnicodeEscape() (rune, bool) {
+	digits := ""
+
+	for i := 0; i < 4; i++ {
+		if !isDigit(l.peekChar()) && !isHexidecimal(l.peekChar()) {
+			return 0, false
+		}
+
+		l.readChar()
+		digits += string(l.ch)
+	}
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return rune(code), true
+}
+
+// newStringToken returns a token for a string or part of a string which started on the given line. If reading the
+// string went wrong, a token.ERROR token describing the problem is returned instead.
+func (l *Lexer) newStringToken(tokenType token.Type, contents string, end rune, problem string, line int) token.Token {
+	tok := token.NewToken(tokenType, contents, line, l.startPosition, l.curLinePosition)
+
+	if end == 0 {
+		problem = "unterminated string"
+	}
+
+	if problem != "" {
+		tok.Type = token.ERROR
+		tok.Literal = fmt.Sprintf("%s (line=%d, col=%d)", problem, line, l.startPosition)
+	}
+
+	return tok
+}
+
+// readStringToken reads a string, starting on its opening quote. The token is on the line the string started on, even if
+// the string spans multiple lines.
+func (l *Lexer) readStringToken(raw bool) token.Token {
+	line := l.curLine
+	contents, end, problem := l.readStringContents(l.ch, raw, false)
+
+	return l.newStringToken(token.STRING, contents, end, problem, line)
+}
+
+// readInterpolatedStringToken reads the start of an interpolated string such as f"Score: {score}/{total}", starting
+// on its opening quote. The text up to the first embedded expression becomes a token.FSTRING_HEAD token, and the
+// expression is then lexed as normal until the `}` which closes it. An interpolated string without any embedded
+// expressions is just a token.STRING.
+func (l *Lexer) readInterpolatedStringToken() token.Token {
+	line := l.curLine
+	quote := l.ch
+
+	contents, end, problem := l.readStringContents(quote, false, true)
+	if end != '{' {
+		return l.newStringToken(token.STRING, contents, end, problem, line)
+	}
+
+	l.interpolations = append(l.interpolations, &interpolation{quote: quote})
+
+	return l.newStringToken(token.FSTRING_HEAD, contents, end, problem, line)
+}
+
+// readInterpolatedStringPart reads the text of an interpolated string following an embedded expression, starting on
+// the `}` which closes the expression. It becomes a token.FSTRING_MID token if another expression follows, or a
+// token.FSTRING_TAIL token if the string ends.
+func (l *Lexer) readInterpolatedStringPart() token.Token {
+	l.startPosition = l.curLinePosition
+	line := l.curLine
+	current := l.interpolations[len(l.interpolations)-1]
+
+	contents, end, problem := l.readStringContents(current.quote, false, true)
+	if end == '{' {
+		return l.newStringToken(token.FSTRING_MID, contents, end, problem, line)
+	}
+
+	l.interpolations = l.interpolations[:len(l.interpolations)-1]
+
+	return l.newStringToken(token.FSTRING_TAIL, contents, end, problem, line)
+}
+
 // newSingleToken returns a new token from a token type.
 func (l *Lexer) newSingleToken(tokenType token.Type) token.Token {
 	return token.NewToken(tokenType, string(l.ch), l.curLine, l.curLinePosition, l.curLinePosition)
@@ -182,9 +385,19 @@ func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
 	for isWhitespace(l.ch) || l.ch == '#' {
-
 		l.skipWhitespace()
-		l.skipComment()
+
+		switch {
+		case l.ch != '#':
+		case l.peekChar() == '[':
+			if errTok, ok := l.skipBlockComment(); !ok {
+				return errTok
+			}
+		case l.isDocComment():
+			return l.readDocComment()
+		default:
+			l.skipComment()
+		}
 	}
 
 	switch l.ch {
@@ -197,6 +410,10 @@ func (l *Lexer) NextToken() token.Token {
 		tok = l.newSingleToken(token.ASTERISK)
 	case '/':
 		tok = l.newSingleToken(token.SLASH)
+	case '|':
+		tok = l.newSingleToken(token.PIPE)
+	case '&':
+		tok = l.newSingleToken(token.AMPERSAND)
 	case ',':
 		tok = l.newSingleToken(token.COMMA)
 	case ':':
@@ -210,10 +427,32 @@ func (l *Lexer) NextToken() token.Token {
 	case ']':
 		tok = l.newSingleToken(token.RBRACKET)
 	case '{':
+		if len(l.interpolations) > 0 {
+			l.interpolations[len(l.interpolations)-1].depth++
+		}
+
 		tok = l.newSingleToken(token.LBRACE)
 	case '}':
+		if len(l.interpolations) > 0 {
+			current := l.interpolations[len(l.interpolations)-1]
+			if current.depth == 0 {
+				tok = l.readInterpolatedStringPart()
+				break
+			}
+
+			current.depth--
+		}
+
 		tok = l.newSingleToken(token.RBRACE)
 	case '.':
+		if strings.HasPrefix(l.input[l.position:], "...") {
+			tok = token.NewToken(token.ELLIPSIS, "...", l.curLine, l.curLinePosition, l.curLinePosition+2)
+			l.readChar()
+			l.readChar()
+
+			break
+		}
+
 		tok = l.newSingleToken(token.DOT)
 
 	// Could be single or double
@@ -317,19 +556,9 @@ func (l *Lexer) NextToken() token.Token {
 		}
 
 	// String handling
-	case '\'':
-		tok.Literal = l.readString('\'')
-		tok.StartCol = l.startPosition
-		tok.EndCol = l.curLinePosition
-		tok.Line = l.curLine
-		tok.Type = token.STRING
-
-	case '"':
-		tok.Literal = l.readString('"')
-		tok.StartCol = l.startPosition
-		tok.EndCol = l.curLinePosition
-		tok.Line = l.curLine
-		tok.Type = token.STRING
+	case '\'', '"':
+		l.startPosition = l.curLinePosition
+		tok = l.readStringToken(false)
 
 	// Newline handling
 	case '\n':
@@ -351,6 +580,21 @@ func (l *Lexer) NextToken() token.Token {
 
 	// Multiple character handling
 	default:
+		// Raw strings, such as r"C:\new", are strings prefixed with an r. Interpolated strings are prefixed with an f.
+		if (l.ch == 'r' || l.ch == 'f') && (l.peekChar() == '"' || l.peekChar() == '\'') {
+			l.startPosition = l.curLinePosition
+			prefix := l.ch
+			l.readChar()
+
+			if prefix == 'r' {
+				tok = l.readStringToken(true)
+			} else {
+				tok = l.readInterpolatedStringToken()
+			}
+
+			break
+		}
+
 		if isValidIdentCharacter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
@@ -370,6 +614,12 @@ func (l *Lexer) NextToken() token.Token {
 			return tok
 		}
 
+		if tokenType, ok := unicodeOperators[l.ch]; ok {
+			tok = l.newSingleToken(tokenType)
+			tok.Literal = string(tokenType)
+			break
+		}
+
 		tok = l.newSingleToken(token.ILLEGAL)
 	}
 
@@ -47,7 +47,10 @@ NOT true OR false AND false XOR true
 a123
 MAP {
     "a": 10,
-}`
+}
+...rest a.b
+SET {1} | s & t
+0.1d 5D 0x1d 5 DIV 2`
 
 	tests := []token.Token{
 		{Type: token.IDENT, Literal: "five", Line: 0, StartCol: 0, EndCol: 3},
@@ -204,7 +207,32 @@ MAP {
 		{Type: token.NEWLINE, Literal: "\n", Line: 38, StartCol: 12},
 		{Type: token.RBRACE, Literal: "}", Line: 39, StartCol: 0},
 
-		{Type: token.EOF, Literal: "", Line: 39, StartCol: 0},
+		{Type: token.NEWLINE, Literal: "\n", Line: 39, StartCol: 1},
+		{Type: token.ELLIPSIS, Literal: "...", Line: 40, StartCol: 0, EndCol: 2},
+		{Type: token.IDENT, Literal: "rest", Line: 40, StartCol: 3, EndCol: 6},
+		{Type: token.IDENT, Literal: "a", Line: 40, StartCol: 8},
+		{Type: token.DOT, Literal: ".", Line: 40, StartCol: 9},
+		{Type: token.IDENT, Literal: "b", Line: 40, StartCol: 10},
+
+		{Type: token.NEWLINE, Literal: "\n", Line: 40, StartCol: 11},
+		{Type: token.SET, Literal: "SET", Line: 41, StartCol: 0, EndCol: 2},
+		{Type: token.LBRACE, Literal: "{", Line: 41, StartCol: 4},
+		{Type: token.INT, Literal: "1", Line: 41, StartCol: 5},
+		{Type: token.RBRACE, Literal: "}", Line: 41, StartCol: 6},
+		{Type: token.PIPE, Literal: "|", Line: 41, StartCol: 8},
+		{Type: token.IDENT, Literal: "s", Line: 41, StartCol: 10},
+		{Type: token.AMPERSAND, Literal: "&", Line: 41, StartCol: 12},
+		{Type: token.IDENT, Literal: "t", Line: 41, StartCol: 14},
+
+		{Type: token.NEWLINE, Literal: "\n", Line: 41, StartCol: 15},
+		{Type: token.DECIMAL, Literal: "0.1d", Line: 42, StartCol: 0, EndCol: 3},
+		{Type: token.DECIMAL, Literal: "5D", Line: 42, StartCol: 5, EndCol: 6},
+		{Type: token.INT, Literal: "0x1d", Line: 42, StartCol: 8, EndCol: 11},
+		{Type: token.INT, Literal: "5", Line: 42, StartCol: 13},
+		{Type: token.DIV, Literal: "DIV", Line: 42, StartCol: 15, EndCol: 17},
+		{Type: token.INT, Literal: "2", Line: 42, StartCol: 19},
+
+		{Type: token.EOF, Literal: "", Line: 42, StartCol: 19},
 	}
 
 	l := New(input)
@@ -224,5 +252,200 @@ MAP {
 		}
 	}
 
-	assert.Equal(t, byte(0), l.peekChar(), "lexer should have read all input before tests finish, not enough test cases")
+	assert.Equal(t, rune(0), l.peekChar(), "lexer should have read all input before tests finish, not enough test cases")
+}
+
+func TestNextTokenUnicode(t *testing.T) {
+	input := `a ← 5
+a ≥ 1 ≤ 2 ≠ 3
+"héllo" × 2 ÷ b
+λ`
+
+	tests := []token.Token{
+		{Type: token.IDENT, Literal: "a", Line: 0, StartCol: 0, EndCol: 0},
+		{Type: token.ASSIGN, Literal: "<-", Line: 0, StartCol: 2, EndCol: 2},
+		{Type: token.INT, Literal: "5", Line: 0, StartCol: 4, EndCol: 4},
+		{Type: token.NEWLINE, Literal: "\n", Line: 0, StartCol: 5, EndCol: 5},
+
+		{Type: token.IDENT, Literal: "a", Line: 1, StartCol: 0, EndCol: 0},
+		{Type: token.GT_EQ, Literal: ">=", Line: 1, StartCol: 2, EndCol: 2},
+		{Type: token.INT, Literal: "1", Line: 1, StartCol: 4, EndCol: 4},
+		{Type: token.LT_EQ, Literal: "<=", Line: 1, StartCol: 6, EndCol: 6},
+		{Type: token.INT, Literal: "2", Line: 1, StartCol: 8, EndCol: 8},
+		{Type: token.NOT_EQ, Literal: "!=", Line: 1, StartCol: 10, EndCol: 10},
+		{Type: token.INT, Literal: "3", Line: 1, StartCol: 12, EndCol: 12},
+		{Type: token.NEWLINE, Literal: "\n", Line: 1, StartCol: 13, EndCol: 13},
+
+		{Type: token.STRING, Literal: "héllo", Line: 2, StartCol: 0, EndCol: 6},
+		{Type: token.ASTERISK, Literal: "*", Line: 2, StartCol: 8, EndCol: 8},
+		{Type: token.INT, Literal: "2", Line: 2, StartCol: 10, EndCol: 10},
+		{Type: token.SLASH, Literal: "/", Line: 2, StartCol: 12, EndCol: 12},
+		{Type: token.IDENT, Literal: "b", Line: 2, StartCol: 14, EndCol: 14},
+		{Type: token.NEWLINE, Literal: "\n", Line: 2, StartCol: 15, EndCol: 15},
+
+		{Type: token.ILLEGAL, Literal: "λ", Line: 3, StartCol: 0, EndCol: 0},
+		{Type: token.EOF, Literal: "", Line: 3, StartCol: 0},
+	}
+
+	l := New(input)
+
+	for _, tt := range tests {
+		tok := l.NextToken()
+
+		assert.Equal(t, tt.Type, tok.Type, "token type wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.Literal, tok.Literal, "token literal wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.Line, tok.Line, "token line number wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.StartCol, tok.StartCol, "token StartCol number wrong for token %s, expecting %s", tok, tt.String())
+
+		if tt.EndCol != 0 {
+			assert.Equal(t, tt.EndCol, tok.EndCol, "token EndCol number wrong for token %s, expecting %s", tok, tt.String())
+		}
+	}
+}
+
+func TestNextTokenStrings(t *testing.T) {
+	input := `"a\nb\tc"
+'it\'s "quoted"'
+"back\\slash \"quote\""
+"\u00e9\u03BB"
+"unknown \q escape"
+r"C:\new\table"
+r'raw \n'
+"line one
+line two" x
+"bad \u12z4" y
+"never closed`
+
+	tests := []token.Token{
+		{Type: token.STRING, Literal: "a\nb\tc", Line: 0, StartCol: 0, EndCol: 8},
+		{Type: token.NEWLINE, Literal: "\n", Line: 0, StartCol: 9},
+		{Type: token.STRING, Literal: "it's \"quoted\"", Line: 1, StartCol: 0, EndCol: 15},
+		{Type: token.NEWLINE, Literal: "\n", Line: 1, StartCol: 16},
+		{Type: token.STRING, Literal: "back\\slash \"quote\"", Line: 2, StartCol: 0},
+		{Type: token.NEWLINE, Literal: "\n", Line: 2, StartCol: 23},
+		{Type: token.STRING, Literal: "éλ", Line: 3, StartCol: 0, EndCol: 13},
+		{Type: token.NEWLINE, Literal: "\n", Line: 3, StartCol: 14},
+		{Type: token.STRING, Literal: "unknown \\q escape", Line: 4, StartCol: 0},
+		{Type: token.NEWLINE, Literal: "\n", Line: 4, StartCol: 19},
+		{Type: token.STRING, Literal: "C:\\new\\table", Line: 5, StartCol: 0, EndCol: 14},
+		{Type: token.NEWLINE, Literal: "\n", Line: 5, StartCol: 15},
+		{Type: token.STRING, Literal: "raw \\n", Line: 6, StartCol: 0, EndCol: 8},
+		{Type: token.NEWLINE, Literal: "\n", Line: 6, StartCol: 9},
+		{Type: token.STRING, Literal: "line one\nline two", Line: 7, StartCol: 0, EndCol: 8},
+		{Type: token.IDENT, Literal: "x", Line: 8, StartCol: 10, EndCol: 10},
+		{Type: token.NEWLINE, Literal: "\n", Line: 8, StartCol: 11},
+		{Type: token.ERROR, Literal: "invalid unicode escape in string, expected \\u followed by 4 hexadecimal digits (line=9, col=0)", Line: 9, StartCol: 0},
+		{Type: token.IDENT, Literal: "y", Line: 9, StartCol: 13},
+		{Type: token.NEWLINE, Literal: "\n", Line: 9, StartCol: 14},
+		{Type: token.ERROR, Literal: "unterminated string (line=10, col=0)", Line: 10, StartCol: 0},
+		{Type: token.EOF, Literal: "", Line: 10, StartCol: 12},
+	}
+
+	l := New(input)
+
+	for _, tt := range tests {
+		tok := l.NextToken()
+
+		assert.Equal(t, tt.Type, tok.Type, "token type wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.Literal, tok.Literal, "token literal wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.Line, tok.Line, "token line number wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.StartCol, tok.StartCol, "token StartCol number wrong for token %s, expecting %s", tok, tt.String())
+
+		if tt.EndCol != 0 {
+			assert.Equal(t, tt.EndCol, tok.EndCol, "token EndCol number wrong for token %s, expecting %s", tok, tt.String())
+		}
+	}
+}
+
+func TestNextTokenInterpolatedStrings(t *testing.T) {
+	input := `f"Score: {score}/{total + 1}!"
+f'{MAP {"a": 1}["a"]}' f"{{plain}}"
+f"{a"`
+
+	tests := []token.Token{
+		{Type: token.FSTRING_HEAD, Literal: "Score: ", Line: 0, StartCol: 0, EndCol: 9},
+		{Type: token.IDENT, Literal: "score", Line: 0, StartCol: 10, EndCol: 14},
+		{Type: token.FSTRING_MID, Literal: "/", Line: 0, StartCol: 15, EndCol: 17},
+		{Type: token.IDENT, Literal: "total", Line: 0, StartCol: 18, EndCol: 22},
+		{Type: token.PLUS, Literal: "+", Line: 0, StartCol: 24, EndCol: 24},
+		{Type: token.INT, Literal: "1", Line: 0, StartCol: 26, EndCol: 26},
+		{Type: token.FSTRING_TAIL, Literal: "!", Line: 0, StartCol: 27, EndCol: 29},
+		{Type: token.NEWLINE, Literal: "\n", Line: 0, StartCol: 30},
+
+		{Type: token.FSTRING_HEAD, Literal: "", Line: 1, StartCol: 0, EndCol: 2},
+		{Type: token.MAP, Literal: "MAP", Line: 1, StartCol: 3},
+		{Type: token.LBRACE, Literal: "{", Line: 1, StartCol: 7},
+		{Type: token.STRING, Literal: "a", Line: 1, StartCol: 8},
+		{Type: token.COLON, Literal: ":", Line: 1, StartCol: 11},
+		{Type: token.INT, Literal: "1", Line: 1, StartCol: 13},
+		{Type: token.RBRACE, Literal: "}", Line: 1, StartCol: 14},
+		{Type: token.LBRACKET, Literal: "[", Line: 1, StartCol: 15},
+		{Type: token.STRING, Literal: "a", Line: 1, StartCol: 16},
+		{Type: token.RBRACKET, Literal: "]", Line: 1, StartCol: 19},
+		{Type: token.FSTRING_TAIL, Literal: "", Line: 1, StartCol: 20, EndCol: 21},
+		{Type: token.STRING, Literal: "{plain}", Line: 1, StartCol: 23, EndCol: 34},
+		{Type: token.NEWLINE, Literal: "\n", Line: 1, StartCol: 35},
+
+		{Type: token.FSTRING_HEAD, Literal: "", Line: 2, StartCol: 0, EndCol: 2},
+		{Type: token.IDENT, Literal: "a", Line: 2, StartCol: 3},
+		{Type: token.ERROR, Literal: "unterminated string (line=2, col=4)", Line: 2, StartCol: 4},
+		{Type: token.EOF, Literal: "", Line: 2, StartCol: 4},
+	}
+
+	l := New(input)
+
+	for _, tt := range tests {
+		tok := l.NextToken()
+
+		assert.Equal(t, tt.Type, tok.Type, "token type wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.Literal, tok.Literal, "token literal wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.Line, tok.Line, "token line number wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.StartCol, tok.StartCol, "token StartCol number wrong for token %s, expecting %s", tok, tt.String())
+
+		if tt.EndCol != 0 {
+			assert.Equal(t, tt.EndCol, tok.EndCol, "token EndCol number wrong for token %s, expecting %s", tok, tt.String())
+		}
+	}
+}
+
+func TestNextTokenComments(t *testing.T) {
+	input := `a # line comment
+#[ block
+comment ]# b
+## Doc line one.
+##   indented
+### banner
+c ## not a doc
+#[ never closed`
+
+	tests := []token.Token{
+		{Type: token.IDENT, Literal: "a", Line: 0, StartCol: 0, EndCol: 0},
+		{Type: token.IDENT, Literal: "b", Line: 2, StartCol: 11, EndCol: 11},
+		{Type: token.NEWLINE, Literal: "\n", Line: 2, StartCol: 12},
+		{Type: token.DOC_COMMENT, Literal: "Doc line one.", Line: 3, StartCol: 0, EndCol: 15},
+		{Type: token.DOC_COMMENT, Literal: "  indented", Line: 4, StartCol: 0, EndCol: 12},
+		{Type: token.IDENT, Literal: "c", Line: 6, StartCol: 0, EndCol: 0},
+		{Type: token.ERROR, Literal: "unterminated block comment (line=7, col=0)", Line: 7, StartCol: 0},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l := New(input)
+
+	for _, tt := range tests {
+		tok := l.NextToken()
+
+		assert.Equal(t, tt.Type, tok.Type, "token type wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.Literal, tok.Literal, "token literal wrong for token %s, expecting %s", tok, tt.String())
+
+		if tt.Type == token.EOF {
+			continue
+		}
+
+		assert.Equal(t, tt.Line, tok.Line, "token line number wrong for token %s, expecting %s", tok, tt.String())
+		assert.Equal(t, tt.StartCol, tok.StartCol, "token StartCol number wrong for token %s, expecting %s", tok, tt.String())
+
+		if tt.EndCol != 0 {
+			assert.Equal(t, tt.EndCol, tok.EndCol, "token EndCol number wrong for token %s, expecting %s", tok, tt.String())
+		}
+	}
 }
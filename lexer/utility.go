@@ -1,30 +1,30 @@
 package lexer
 
-import "strings"
+import "unicode"
 
-// isLetter returns true if the given character (byte) is a letter.
-func isLetter(ch byte) bool {
+// isLetter returns true if the given character is an ASCII letter.
+func isLetter(ch rune) bool {
 	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
 }
 
 // isDigit returns true if the character is a number.
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
 // isHexidecimal returns true if the characters is any character a...f.
-func isHexidecimal(ch byte) bool {
-	lower := strings.ToLower(string(ch))[0]
+func isHexidecimal(ch rune) bool {
+	lower := unicode.ToLower(ch)
 	return 'a' <= lower && lower <= 'f'
 }
 
 // isWhitespace returns true if the character is a type of whitespace (a space, a tab or a linefeed)
 // Newlines are handled by the lexer as they are used for automatic semicolon insertion.
-func isWhitespace(ch byte) bool {
+func isWhitespace(ch rune) bool {
 	return ch == ' ' || ch == '\t' || ch == '\r'
 }
 
 // isValidIdentCharacter returns true if the character is valid inside an identifier (a character or an underscore)
-func isValidIdentCharacter(ch byte) bool {
+func isValidIdentCharacter(ch rune) bool {
 	return isLetter(ch) || ch == '_'
 }
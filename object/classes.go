@@ -0,0 +1,148 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ollybritton/aqa/ast"
+)
+
+// Class is a class defined inside a program, which instances can be created from using NEW.
+type Class struct {
+	Name    string
+	Parent  *Class // nil if the class doesn't inherit from another.
+	Fields  []*ClassField
+	Methods map[string]*Method
+	Env     *Environment // The environment the class was defined in, which field defaults are evaluated inside.
+}
+
+func (c *Class) Type() Type { return CLASS_OBJ }
+func (c *Class) Inspect() string {
+	if c.Parent != nil {
+		return fmt.Sprintf("<class %s(%s)>", c.Name, c.Parent.Name)
+	}
+
+	return fmt.Sprintf("<class %s>", c.Name)
+}
+
+// FindMethod looks up a method by name, searching the parent classes if the class doesn't define it itself.
+func (c *Class) FindMethod(name string) (*Method, bool) {
+	for class := c; class != nil; class = class.Parent {
+		if method, ok := class.Methods[name]; ok {
+			return method, true
+		}
+	}
+
+	return nil, false
+}
+
+// FindField looks up a field by name, searching the parent classes if the class doesn't declare it itself.
+func (c *Class) FindField(name string) (*ClassField, bool) {
+	for class := c; class != nil; class = class.Parent {
+		for _, field := range class.Fields {
+			if field.Name == name {
+				return field, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// AllFields returns every field an instance of the class has, starting with the fields of the oldest ancestor. A field
+// redeclared by a subclass replaces the parent's declaration but keeps its position.
+func (c *Class) AllFields() []*ClassField {
+	var fields []*ClassField
+	if c.Parent != nil {
+		fields = c.Parent.AllFields()
+	}
+
+outer:
+	for _, field := range c.Fields {
+		for i, existing := range fields {
+			if existing.Name == field.Name {
+				fields[i] = field
+				continue outer
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// IsSubclassOf returns true if the class is the same as other or inherits from it.
+func (c *Class) IsSubclassOf(other *Class) bool {
+	for class := c; class != nil; class = class.Parent {
+		if class == other {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClassField is a field declared inside a class.
+type ClassField struct {
+	Name    string
+	Default ast.Expression // nil if the field has no default value.
+	Private bool
+	Class   *Class // The class which declared the field.
+}
+
+// Method is a method declared inside a class.
+type Method struct {
+	Name       string
+	Subroutine *Subroutine
+	Private    bool
+	Class      *Class // The class which declared the method.
+}
+
+// Instance is an instance of a class.
+type Instance struct {
+	Class  *Class
+	Fields map[string]Object
+}
+
+func (i *Instance) Type() Type { return INSTANCE_OBJ }
+func (i *Instance) Inspect() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, field := range i.Class.AllFields() {
+		fields = append(fields, fmt.Sprintf("%s: %s", field.Name, i.Fields[field.Name].Inspect()))
+	}
+
+	out.WriteString(i.Class.Name)
+	out.WriteString("(")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// BoundMethod is a method which has been looked up on an instance, so that it can be called with `self` set to the
+// instance.
+type BoundMethod struct {
+	Self   *Instance
+	Method *Method
+}
+
+func (bm *BoundMethod) Type() Type { return METHOD_OBJ }
+func (bm *BoundMethod) Inspect() string {
+	return fmt.Sprintf("<method %s.%s>", bm.Method.Class.Name, bm.Method.Name)
+}
+
+// Super is the value of SUPER inside a method, which looks up methods starting from the parent of the class that
+// declared the method.
+type Super struct {
+	Self  *Instance
+	Class *Class
+}
+
+func (s *Super) Type() Type { return SUPER_OBJ }
+func (s *Super) Inspect() string {
+	return fmt.Sprintf("<super %s>", s.Class.Name)
+}
@@ -8,15 +8,21 @@ import (
 type Environment struct {
 	store     map[string]Object
 	constants map[string]Object
+	docs      map[string]string
 	outer     *Environment
 	modules   []*Module
+
+	// class is the class whose method is running in this environment, or nil outside of a method. It is kept apart from
+	// the variables so that a program can't pretend to be inside a method by assigning to them.
+	class *Class
 }
 
 // NewEnvironment creates a new environment.
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
 	c := make(map[string]Object)
-	return &Environment{store: s, constants: c, modules: []*Module{}, outer: nil}
+	d := make(map[string]string)
+	return &Environment{store: s, constants: c, docs: d, modules: []*Module{}, outer: nil}
 }
 
 // NewEnclosedEnvironment creates a new enclosed environment, extending from a previous.
@@ -27,6 +33,23 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+// SetClass records that the environment belongs to a call to a method of the given class.
+func (e *Environment) SetClass(class *Class) {
+	e.class = class
+}
+
+// Class returns the class whose method is running, looking in the outer environment so that an FN created inside a
+// method also counts as part of it. It returns nil outside of a method.
+func (e *Environment) Class() *Class {
+	for env := e; env != nil; env = env.outer {
+		if env.class != nil {
+			return env.class
+		}
+	}
+
+	return nil
+}
+
 // Get gets an object by name.
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
@@ -100,3 +123,31 @@ func (e *Environment) SetConstant(name string, value Object) Object {
 func (e *Environment) AddModule(module *Module) {
 	e.modules = append(e.modules, module)
 }
+
+// SetDoc records the documentation comment of a subroutine or constant. An empty doc records that it has none, which
+// replaces the documentation of anything it was defined with before.
+func (e *Environment) SetDoc(name string, doc string) {
+	e.docs[name] = doc
+}
+
+// Doc gets the documentation comment of a subroutine or constant by name. Like Get, it also looks in the outer
+// environment and the exposed parts of any modules.
+func (e *Environment) Doc(name string) (string, bool) {
+	if doc, ok := e.docs[name]; ok {
+		return doc, doc != ""
+	}
+
+	if e.outer != nil {
+		if doc, ok := e.outer.Doc(name); ok {
+			return doc, ok
+		}
+	}
+
+	for _, module := range e.modules {
+		if doc, ok := module.Env.Doc(name); ok && module.IsExposed(name) {
+			return doc, ok
+		}
+	}
+
+	return "", false
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
+	"math/big"
+	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -28,8 +31,15 @@ func (b *Boolean) HashKey() HashKey {
 	return HashKey{b.Type(), 0}
 }
 
-// HashKey gets the hash value of an integer.
+// HashKey gets the hash value of an integer. Integers too big for an int64 are hashed from their digits.
 func (i *Integer) HashKey() HashKey {
+	if i.Big != nil {
+		h := fnv.New64a()
+		h.Write([]byte(i.Big.String()))
+
+		return HashKey{i.Type(), h.Sum64()}
+	}
+
 	return HashKey{i.Type(), uint64(i.Value)}
 }
 
@@ -38,6 +48,19 @@ func (f *Float) HashKey() HashKey {
 	return HashKey{f.Type(), math.Float64bits(f.Value)}
 }
 
+// HashKey gets the hash value of a decimal. A whole number decimal is equal to the integer with the same value, so it
+// has the same hash value as that integer.
+func (d *Decimal) HashKey() HashKey {
+	if d.Value.IsInt() {
+		return NewBigInteger(new(big.Int).Set(d.Value.Num())).HashKey()
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(d.Value.String()))
+
+	return HashKey{d.Type(), h.Sum64()}
+}
+
 // HashKey gets the hash value of a string.
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
@@ -46,6 +69,12 @@ func (s *String) HashKey() HashKey {
 	return HashKey{s.Type(), h.Sum64()}
 }
 
+// HashKey gets the hash value of an enum member. Members are only equal if they are the same object, so the key comes
+// from the address of the member rather than its name, which another enum with the same name could share.
+func (em *EnumMember) HashKey() HashKey {
+	return HashKey{em.Type(), uint64(reflect.ValueOf(em).Pointer())}
+}
+
 // HashPair represents a key-value pair for a key and value.
 type HashPair struct {
 	Key   Object
@@ -61,6 +90,21 @@ func (h *Hash) Type() Type {
 	return HASH_OBJ
 }
 
+// SortedPairs returns the pairs in the hash ordered by their keys, so that iterating over a hash always happens in the
+// same order. Numeric keys are ordered by value, and other keys by type and then by their string representation.
+func (h *Hash) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Pairs))
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return lessKey(pairs[i].Key, pairs[j].Key)
+	})
+
+	return pairs
+}
+
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 
@@ -75,3 +119,159 @@ func (h *Hash) Inspect() string {
 
 	return out.String()
 }
+
+// Set is an unordered collection of distinct objects. Like the keys of a hash, every element must be Hashable.
+type Set struct {
+	Elements map[HashKey]Object
+}
+
+// NewSet creates an empty set.
+func NewSet() *Set {
+	return &Set{Elements: make(map[HashKey]Object)}
+}
+
+func (s *Set) Type() Type {
+	return SET_OBJ
+}
+
+// Add adds an object to the set. It returns false if the object can't be stored in a set because it isn't Hashable.
+func (s *Set) Add(obj Object) bool {
+	key, ok := obj.(Hashable)
+	if !ok {
+		return false
+	}
+
+	s.Elements[key.HashKey()] = obj
+	return true
+}
+
+// Remove removes an object from the set, if it is there.
+func (s *Set) Remove(obj Object) {
+	if key, ok := obj.(Hashable); ok {
+		delete(s.Elements, key.HashKey())
+	}
+}
+
+// Contains reports whether an object is in the set.
+func (s *Set) Contains(obj Object) bool {
+	key, ok := obj.(Hashable)
+	if !ok {
+		return false
+	}
+
+	_, ok = s.Elements[key.HashKey()]
+	return ok
+}
+
+// Union returns a new set containing the elements which are in either set.
+func (s *Set) Union(other *Set) *Set {
+	result := NewSet()
+	for key, el := range s.Elements {
+		result.Elements[key] = el
+	}
+
+	for key, el := range other.Elements {
+		result.Elements[key] = el
+	}
+
+	return result
+}
+
+// Intersection returns a new set containing the elements which are in both sets.
+func (s *Set) Intersection(other *Set) *Set {
+	result := NewSet()
+	for key, el := range s.Elements {
+		if _, ok := other.Elements[key]; ok {
+			result.Elements[key] = el
+		}
+	}
+
+	return result
+}
+
+// Difference returns a new set containing the elements which are in this set but not the other.
+func (s *Set) Difference(other *Set) *Set {
+	result := NewSet()
+	for key, el := range s.Elements {
+		if _, ok := other.Elements[key]; !ok {
+			result.Elements[key] = el
+		}
+	}
+
+	return result
+}
+
+// Equals reports whether both sets contain exactly the same elements.
+func (s *Set) Equals(other *Set) bool {
+	if len(s.Elements) != len(other.Elements) {
+		return false
+	}
+
+	for key := range s.Elements {
+		if _, ok := other.Elements[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SortedElements returns the elements of the set in a fixed order, so that printing or iterating over a set always
+// gives the same result. Numbers are ordered by value and everything else by type and then string representation.
+func (s *Set) SortedElements() []Object {
+	elements := make([]Object, 0, len(s.Elements))
+	for _, el := range s.Elements {
+		elements = append(elements, el)
+	}
+
+	sort.Slice(elements, func(i, j int) bool {
+		return lessKey(elements[i], elements[j])
+	})
+
+	return elements
+}
+
+func (s *Set) Inspect() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range s.SortedElements() {
+		elements = append(elements, el.Inspect())
+	}
+
+	out.WriteString("SET {")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// lessKey orders the keys of hashes and the elements of sets. Numbers are ordered by value, and everything else by type
+// and then by its string representation.
+func lessKey(a, b Object) bool {
+	aVal, aNumeric := numericValue(a)
+	bVal, bNumeric := numericValue(b)
+
+	switch {
+	case aNumeric && bNumeric && aVal != bVal:
+		return aVal < bVal
+	case a.Type() != b.Type():
+		return a.Type() < b.Type()
+	default:
+		return a.Inspect() < b.Inspect()
+	}
+}
+
+// numericValue returns the value of an integer, float or decimal as a float64, so that they can be compared.
+func numericValue(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return IntegerToFloat(obj).Value, true
+	case *Float:
+		return obj.Value, true
+	case *Decimal:
+		return DecimalToFloat(obj).Value, true
+	default:
+		return 0, false
+	}
+}
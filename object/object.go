@@ -3,10 +3,13 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 
 	"github.com/ollybritton/aqa/ast"
+	"github.com/ollybritton/aqa/token"
 )
 
 // Type represents a type of object, such as an integer or a subroutine.
@@ -14,19 +17,43 @@ type Type string
 
 // Definition of object types.
 const (
-	INTEGER_OBJ  = "INTEGER"
-	FLOAT_OBJ    = "FLOAT"
-	BOOLEAN_OBJ  = "BOOLEAN"
-	FUNCTION_OBJ = "FUNCTION"
-	STRING_OBJ   = "STRING"
-	ARRAY_OBJ    = "ARRAY"
-	HASH_OBJ     = "HASH"
-	MODULE_OBJ   = "MODULE"
-
-	RETURN_VALUE_OBJ = "RETURN_VALUE"
-	BUILTIN_OBJ      = "BUILTIN"
-	ERROR_OBJ        = "ERROR"
-	NULL_OBJ         = "NULL"
+	INTEGER_OBJ   = "INTEGER"
+	FLOAT_OBJ     = "FLOAT"
+	DECIMAL_OBJ   = "DECIMAL"
+	BOOLEAN_OBJ   = "BOOLEAN"
+	FUNCTION_OBJ  = "FUNCTION"
+	GENERATOR_OBJ = "GENERATOR"
+	STRING_OBJ    = "STRING"
+	ARRAY_OBJ     = "ARRAY"
+	HASH_OBJ      = "HASH"
+	SET_OBJ       = "SET"
+	MODULE_OBJ    = "MODULE"
+
+	RECORD_TYPE_OBJ = "RECORD_TYPE"
+	RECORD_OBJ      = "RECORD"
+
+	ENUM_TYPE_OBJ = "ENUM_TYPE"
+	ENUM_OBJ      = "ENUM"
+
+	CLASS_OBJ    = "CLASS"
+	INSTANCE_OBJ = "INSTANCE"
+	METHOD_OBJ   = "METHOD"
+	SUPER_OBJ    = "SUPER"
+
+	BUILTIN_OBJ = "BUILTIN"
+	ERROR_OBJ   = "ERROR"
+	NULL_OBJ    = "NULL"
+)
+
+// Kinds of error, which programs can use to tell apart the errors they catch.
+const (
+	RUNTIME_ERROR  = "RuntimeError"
+	TYPE_ERROR     = "TypeError"
+	NAME_ERROR     = "NameError"
+	INDEX_ERROR    = "IndexError"
+	DIVISION_ERROR = "DivisionError"
+	ARGUMENT_ERROR = "ArgumentError"
+	THROWN_ERROR   = "Error" // An error raised by the program using THROW.
 )
 
 // Object is an interface which allows different objects to be represented.
@@ -46,13 +73,46 @@ type Builtin struct {
 func (b *Builtin) Type() Type      { return BUILTIN_OBJ }
 func (b *Builtin) Inspect() string { return "<builtin>" }
 
-// Integer represents an integer within the program.
+// Integer represents an integer within the program. Integers which don't fit in an int64 are kept in Big instead, and
+// Value is clamped to the nearest int64 so that code which only deals with small integers, like indexing, still sees
+// a value that is out of range.
 type Integer struct {
 	Value int64
+	Big   *big.Int
+}
+
+// NewBigInteger creates an integer from a big.Int, only keeping the big.Int if the value doesn't fit in an int64. The
+// big.Int must not be modified afterwards.
+func NewBigInteger(val *big.Int) *Integer {
+	if val.IsInt64() {
+		return &Integer{Value: val.Int64()}
+	}
+
+	clamped := int64(math.MaxInt64)
+	if val.Sign() < 0 {
+		clamped = math.MinInt64
+	}
+
+	return &Integer{Value: clamped, Big: val}
+}
+
+// BigInt returns the value of the integer as a new big.Int, which can be modified freely.
+func (i *Integer) BigInt() *big.Int {
+	if i.Big != nil {
+		return new(big.Int).Set(i.Big)
+	}
+
+	return big.NewInt(i.Value)
 }
 
-func (i *Integer) Type() Type      { return INTEGER_OBJ }
-func (i *Integer) Inspect() string { return fmt.Sprintf("%d", i.Value) }
+func (i *Integer) Type() Type { return INTEGER_OBJ }
+func (i *Integer) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
+
+	return fmt.Sprintf("%d", i.Value)
+}
 
 // Float represents an Float within the program.
 type Float struct {
@@ -62,6 +122,61 @@ type Float struct {
 func (f *Float) Type() Type      { return FLOAT_OBJ }
 func (f *Float) Inspect() string { return strconv.FormatFloat(f.Value, 'f', -1, 64) }
 
+// Decimal represents an exact number within the program, such as 0.1d. It is stored as a fraction, so arithmetic on
+// decimals never loses precision.
+type Decimal struct {
+	Value *big.Rat
+}
+
+func (d *Decimal) Type() Type { return DECIMAL_OBJ }
+
+// Inspect writes the decimal out in full, such as 0.3. Fractions which can't be written exactly as a decimal, like a
+// third, are written as a fraction instead, such as 1/3.
+func (d *Decimal) Inspect() string {
+	if d.Value.IsInt() {
+		return d.Value.Num().String()
+	}
+
+	if places, ok := decimalPlaces(d.Value.Denom()); ok {
+		return d.Value.FloatString(places)
+	}
+
+	return d.Value.String()
+}
+
+// decimalPlaces works out how many digits are needed after the decimal point to write a fraction with the given
+// denominator exactly. This is only possible if the denominator has no prime factors other than 2 and 5.
+func decimalPlaces(denom *big.Int) (int, bool) {
+	rest := new(big.Int).Set(denom)
+	rem := new(big.Int)
+
+	count := func(factor int64) int {
+		n := 0
+		f := big.NewInt(factor)
+
+		for {
+			quo, _ := new(big.Int).QuoRem(rest, f, rem)
+			if rem.Sign() != 0 {
+				return n
+			}
+
+			rest = quo
+			n++
+		}
+	}
+
+	twos, fives := count(2), count(5)
+	if rest.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+
+	if twos > fives {
+		return twos, true
+	}
+
+	return fives, true
+}
+
 // Boolean represents a boolean value, such as true or false, within the program.
 type Boolean struct {
 	Value bool
@@ -76,28 +191,48 @@ type Null struct{}
 func (n *Null) Type() Type      { return NULL_OBJ }
 func (n *Null) Inspect() string { return "null" }
 
-// ReturnValue represents a value that is being returned from a subroutine or from a program as a whole.
-type ReturnValue struct {
-	Value Object
-}
-
-func (rv *ReturnValue) Type() Type      { return RETURN_VALUE_OBJ }
-func (rv *ReturnValue) Inspect() string { return rv.Value.Inspect() }
-
 // Error represents an error that occurs during the evalutation of the programming language.
 type Error struct {
 	Message string
+	Kind    string       // The kind of error, such as TYPE_ERROR. Errors without a kind are runtime errors.
+	Tok     *token.Token // Where the error occured, or nil if that isn't known yet.
+}
+
+func (e *Error) Type() Type { return ERROR_OBJ }
+
+// Inspect returns the message of the error, followed by where it occured if that is known.
+func (e *Error) Inspect() string {
+	if e.Tok == nil {
+		return "ERROR: " + e.Message
+	}
+
+	return fmt.Sprintf("ERROR: %s (line=%d, col=%d)", e.Message, e.Tok.Line, e.Tok.StartCol)
 }
 
-func (e *Error) Type() Type      { return ERROR_OBJ }
-func (e *Error) Inspect() string { return "ERROR: " + e.Message }
+// ErrorKind returns the kind of the error, which is RUNTIME_ERROR if no kind was given.
+func (e *Error) ErrorKind() string {
+	if e.Kind == "" {
+		return RUNTIME_ERROR
+	}
 
-// Subroutine represents a subroutine within the evaluator.
+	return e.Kind
+}
+
+// ErrorRecord is the type of the records which errors are turned into when they are caught using TRY ... CATCH.
+var ErrorRecord = &RecordType{
+	Name:       "Error",
+	Fields:     []string{"message", "kind", "line", "column"},
+	FieldTypes: []string{"String", "String", "Integer", "Integer"},
+}
+
+// Subroutine represents a subroutine within the evaluator. Anonymous functions created using FN are also represented as
+// subroutines, but have a nil Name.
 type Subroutine struct {
 	Name       *ast.Identifier
-	Parameters []*ast.Identifier
+	Parameters []*ast.Parameter
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Generator  bool // True if the body contains a YIELD, so that calling the subroutine returns a Generator.
 }
 
 func (s *Subroutine) Type() Type { return FUNCTION_OBJ }
@@ -107,9 +242,26 @@ func (s *Subroutine) Inspect() string {
 		args = append(args, arg.String())
 	}
 
+	if s.Name == nil {
+		return fmt.Sprintf("<fn(%s)>", strings.Join(args, ", "))
+	}
+
 	return fmt.Sprintf("<subroutine %s(%s)>", s.Name, strings.Join(args, ", "))
 }
 
+// Generator represents the lazy sequence of values returned by calling a subroutine which contains YIELD. The body of
+// the subroutine only runs when the next value is asked for, and pauses again at the following YIELD.
+type Generator struct {
+	Name string
+
+	// Resume runs the body of the subroutine until its next YIELD and returns the value yielded. It returns false once
+	// the body has finished. An error inside the body is returned as the value, after which the generator is finished.
+	Resume func() (Object, bool)
+}
+
+func (g *Generator) Type() Type      { return GENERATOR_OBJ }
+func (g *Generator) Inspect() string { return fmt.Sprintf("<generator %s>", g.Name) }
+
 // String represents a string within the evaluator.
 type String struct {
 	Value string
@@ -139,6 +291,95 @@ func (a *Array) Inspect() string {
 	return out.String()
 }
 
+// RecordType represents the definition of a record, which is called like a subroutine to create new records.
+type RecordType struct {
+	Name       string
+	Fields     []string
+	FieldTypes []string // The declared type of each field, or "" if no type was given.
+}
+
+func (rt *RecordType) Type() Type { return RECORD_TYPE_OBJ }
+func (rt *RecordType) Inspect() string {
+	return fmt.Sprintf("<record %s(%s)>", rt.Name, strings.Join(rt.Fields, ", "))
+}
+
+// HasField returns true if the record type has a field with the given name.
+func (rt *RecordType) HasField(name string) bool {
+	return rt.FieldIndex(name) != -1
+}
+
+// FieldIndex returns the position of the field with the given name, or -1 if the record type doesn't have it.
+func (rt *RecordType) FieldIndex(name string) int {
+	for i, field := range rt.Fields {
+		if field == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Record represents an instance of a record type within the evaluator.
+type Record struct {
+	RecordType *RecordType
+	Fields     map[string]Object
+}
+
+func (r *Record) Type() Type { return RECORD_OBJ }
+func (r *Record) Inspect() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, name := range r.RecordType.Fields {
+		fields = append(fields, fmt.Sprintf("%s: %s", name, r.Fields[name].Inspect()))
+	}
+
+	out.WriteString(r.RecordType.Name)
+	out.WriteString("(")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// EnumType represents the definition of an enum, which holds its members in the order they were declared.
+type EnumType struct {
+	Name    string
+	Members []*EnumMember
+}
+
+func (et *EnumType) Type() Type { return ENUM_TYPE_OBJ }
+func (et *EnumType) Inspect() string {
+	names := []string{}
+	for _, m := range et.Members {
+		names = append(names, m.Name)
+	}
+
+	return fmt.Sprintf("<enum %s(%s)>", et.Name, strings.Join(names, ", "))
+}
+
+// Member returns the member of the enum with the given name.
+func (et *EnumType) Member(name string) (*EnumMember, bool) {
+	for _, m := range et.Members {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// EnumMember represents one of the values of an enum. There is only ever one EnumMember for each value, so two members
+// are equal if they are the same object.
+type EnumMember struct {
+	EnumType *EnumType
+	Name     string
+	Ordinal  int // The position of the member in the enum definition, starting from 0.
+}
+
+func (em *EnumMember) Type() Type      { return ENUM_OBJ }
+func (em *EnumMember) Inspect() string { return em.EnumType.Name + "." + em.Name }
+
 // Module represents a module, which could either be a file or directory.
 type Module struct {
 	Env     *Environment
@@ -1,6 +1,9 @@
 package object
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello"}
@@ -20,3 +23,77 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestSetInspect(t *testing.T) {
+	set := NewSet()
+	for _, el := range []Object{&String{Value: "b"}, &Integer{Value: 10}, &Float{Value: 2.5}, &Integer{Value: 2}, &String{Value: "a"}, &Integer{Value: 2}} {
+		set.Add(el)
+	}
+
+	expected := "SET {2, 2.5, 10, a, b}"
+	if set.Inspect() != expected {
+		t.Errorf("set.Inspect() wrong. expected=%q, got=%q", expected, set.Inspect())
+	}
+
+	if set.Add(&Array{}) {
+		t.Errorf("arrays should not be addable to a set")
+	}
+}
+
+func TestBigIntegerHashKey(t *testing.T) {
+	n, _ := new(big.Int).SetString("18446744073709551616", 10)
+	m, _ := new(big.Int).SetString("18446744073709551616", 10)
+
+	if NewBigInteger(n).HashKey() != NewBigInteger(m).HashKey() {
+		t.Errorf("big integers with same value have different hash keys")
+	}
+
+	if NewBigInteger(big.NewInt(5)).HashKey() != (&Integer{Value: 5}).HashKey() {
+		t.Errorf("small integer created from a big.Int has a different hash key")
+	}
+
+	if NewBigInteger(big.NewInt(5)).Big != nil {
+		t.Errorf("integer which fits in an int64 should not keep its big.Int")
+	}
+}
+
+func TestDecimalInspect(t *testing.T) {
+	tests := []struct {
+		value    *big.Rat
+		expected string
+	}{
+		{big.NewRat(3, 10), "0.3"},
+		{big.NewRat(5, 1), "5"},
+		{big.NewRat(-1, 8), "-0.125"},
+		{big.NewRat(1, 3), "1/3"},
+		{big.NewRat(1, 40), "0.025"},
+	}
+
+	for _, tt := range tests {
+		d := &Decimal{Value: tt.value}
+		if d.Inspect() != tt.expected {
+			t.Errorf("decimal.Inspect() wrong. expected=%q, got=%q", tt.expected, d.Inspect())
+		}
+	}
+}
+
+func TestEnumMemberHashKey(t *testing.T) {
+	colour := &EnumType{Name: "Colour"}
+	colour.Members = []*EnumMember{{EnumType: colour, Name: "RED"}, {EnumType: colour, Name: "BLUE", Ordinal: 1}}
+
+	// An enum with the same name, such as one defined in another module.
+	other := &EnumType{Name: "Colour"}
+	other.Members = []*EnumMember{{EnumType: other, Name: "RED"}}
+
+	if colour.Members[0].HashKey() != colour.Members[0].HashKey() {
+		t.Errorf("the same enum member has different hash keys")
+	}
+
+	if colour.Members[0].HashKey() == colour.Members[1].HashKey() {
+		t.Errorf("different members of the same enum have the same hash key")
+	}
+
+	if colour.Members[0].HashKey() == other.Members[0].HashKey() {
+		t.Errorf("members of different enums with the same name have the same hash key")
+	}
+}
@@ -1,26 +1,83 @@
 package object
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 )
 
 // IntegerToFloat converts an integer object to a float object.
 func IntegerToFloat(i *Integer) *Float {
+	if i.Big != nil {
+		val, _ := new(big.Float).SetInt(i.Big).Float64()
+		return &Float{Value: val}
+	}
+
 	return &Float{Value: float64(i.Value)}
 }
 
 // IntegerToString converts an integer to a string.
 func IntegerToString(i *Integer) *String {
-	val := fmt.Sprintf("%d", i.Value)
-	return &String{Value: val}
+	return &String{Value: i.Inspect()}
 }
 
 // FloatToInteger converts a flaot object to an integer object.
 func FloatToInteger(f *Float) *Integer {
-	val := int64(math.Round(f.Value))
-	return &Integer{Value: val}
+	return IntegerFromFloat(math.Round(f.Value))
+}
+
+// IntegerFromFloat converts a whole number stored as a float64 into an integer object, using a big integer if it is too
+// large for an int64. Any fractional part is discarded.
+func IntegerFromFloat(f float64) *Integer {
+	if f >= math.MinInt64 && f < math.MaxInt64 {
+		return &Integer{Value: int64(f)}
+	}
+
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return &Integer{}
+	}
+
+	val, _ := big.NewFloat(f).Int(nil)
+	return NewBigInteger(val)
+}
+
+// IntegerToDecimal converts an integer object to a decimal object.
+func IntegerToDecimal(i *Integer) *Decimal {
+	return &Decimal{Value: new(big.Rat).SetInt(i.BigInt())}
+}
+
+// DecimalToFloat converts a decimal object to the nearest float object.
+func DecimalToFloat(d *Decimal) *Float {
+	val, _ := d.Value.Float64()
+	return &Float{Value: val}
+}
+
+// FloatToDecimal converts a float object to a decimal object. The decimal is the shortest number which the float is
+// the closest float to, so 0.1 becomes exactly 0.1 rather than the binary value the float really holds.
+func FloatToDecimal(f *Float) (*Decimal, error) {
+	if math.IsInf(f.Value, 0) || math.IsNaN(f.Value) {
+		return &Decimal{}, fmt.Errorf("cannot convert %s to a decimal", f.Inspect())
+	}
+
+	val, _ := new(big.Rat).SetString(strconv.FormatFloat(f.Value, 'g', -1, 64))
+	return &Decimal{Value: val}, nil
+}
+
+// DecimalToString converts a decimal into a string.
+func DecimalToString(d *Decimal) *String {
+	return &String{Value: d.Inspect()}
+}
+
+// StringToDecimal converts a string such as "0.1" or "1/3" to a decimal.
+func StringToDecimal(s *String) (*Decimal, error) {
+	val, ok := new(big.Rat).SetString(s.Value)
+	if !ok {
+		return &Decimal{}, fmt.Errorf("cannot convert %q to a decimal", s.Value)
+	}
+
+	return &Decimal{Value: val}, nil
 }
 
 // FloatToString converts a float into a string.
@@ -29,9 +86,15 @@ func FloatToString(f *Float) *String {
 	return &String{Value: val}
 }
 
-// StringToInteger converts a string to an integer.
+// StringToInteger converts a string to an integer. Numbers too large for an int64 become big integers.
 func StringToInteger(s *String) (*Integer, error) {
 	val, err := strconv.ParseInt(s.Value, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if n, ok := new(big.Int).SetString(s.Value, 0); ok {
+			return NewBigInteger(n), nil
+		}
+	}
+
 	if err != nil {
 		return &Integer{}, err
 	}
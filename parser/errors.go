@@ -111,6 +111,32 @@ func NewFloatParseError(curTok, peekTok token.Token, value string) FloatParseErr
 	}
 }
 
+// DecimalParseError represents an error that occurs when trying to parse a string into an exact decimal.
+type DecimalParseError struct {
+	Message string
+
+	CurTok  token.Token
+	PeekTok token.Token
+	Value   string
+}
+
+func (e DecimalParseError) Error() string {
+	return e.Message
+}
+
+// NewDecimalParseError returns a new DecimalParseError.
+func NewDecimalParseError(curTok, peekTok token.Token, value string) DecimalParseError {
+	msg := fmt.Sprintf("could not parse %q as decimal (line=%d, startcol=%d, endcol=%d)", value, curTok.Line, curTok.StartCol, curTok.EndCol)
+
+	return DecimalParseError{
+		Message: msg,
+
+		CurTok:  curTok,
+		PeekTok: peekTok,
+		Value:   value,
+	}
+}
+
 // NoPrefixParseFnError represents an error that occurs when the parser encounters a token it doesn't have a prefix parse
 // function for.
 type NoPrefixParseFnError struct {
@@ -137,3 +163,128 @@ func NewNoPrefixParseFnError(curTok, peekTok token.Token, unknown token.Type) No
 		UnknownType: unknown,
 	}
 }
+
+// InvalidAssignmentTargetError represents an error that occurs when the left-hand side of an assignment is something that
+// cannot be assigned to, such as `5 <- 10`.
+type InvalidAssignmentTargetError struct {
+	Message string
+
+	CurTok  token.Token
+	PeekTok token.Token
+	Target  string
+}
+
+func (e InvalidAssignmentTargetError) Error() string {
+	return e.Message
+}
+
+// NewInvalidAssignmentTargetError returns a new InvalidAssignmentTargetError.
+func NewInvalidAssignmentTargetError(curTok, peekTok token.Token, target string) InvalidAssignmentTargetError {
+	msg := fmt.Sprintf("cannot assign to %s (line=%d, startcol=%d, endcol=%d)", target, peekTok.Line, peekTok.StartCol, peekTok.EndCol)
+
+	return InvalidAssignmentTargetError{
+		Message: msg,
+
+		CurTok:  curTok,
+		PeekTok: peekTok,
+		Target:  target,
+	}
+}
+
+// InvalidParameterError represents an error that occurs when a parameter of a subroutine or FN isn't allowed where it
+// is, such as a rest parameter which isn't the last parameter.
+type InvalidParameterError struct {
+	Message string
+
+	CurTok    token.Token
+	PeekTok   token.Token
+	Parameter string
+}
+
+func (e InvalidParameterError) Error() string {
+	return e.Message
+}
+
+// NewInvalidParameterError returns a new InvalidParameterError. The curTok should be the name of the parameter.
+func NewInvalidParameterError(curTok, peekTok token.Token, parameter string, problem string) InvalidParameterError {
+	msg := fmt.Sprintf("invalid parameter %s: %s (line=%d, startcol=%d, endcol=%d)", parameter, problem, curTok.Line, curTok.StartCol, curTok.EndCol)
+
+	return InvalidParameterError{
+		Message: msg,
+
+		CurTok:    curTok,
+		PeekTok:   peekTok,
+		Parameter: parameter,
+	}
+}
+
+// OutsideLoopError represents an error that occurs when a BREAK or CONTINUE statement is used outside of a loop.
+type OutsideLoopError struct {
+	Message string
+
+	CurTok  token.Token
+	PeekTok token.Token
+}
+
+func (e OutsideLoopError) Error() string {
+	return e.Message
+}
+
+// NewOutsideLoopError returns a new OutsideLoopError.
+func NewOutsideLoopError(curTok, peekTok token.Token) OutsideLoopError {
+	msg := fmt.Sprintf("'%s' outside of a loop (line=%d, startcol=%d, endcol=%d)", curTok.Literal, curTok.Line, curTok.StartCol, curTok.EndCol)
+
+	return OutsideLoopError{
+		Message: msg,
+
+		CurTok:  curTok,
+		PeekTok: peekTok,
+	}
+}
+
+// OutsideSubroutineError represents an error that occurs when a YIELD statement is used outside of a subroutine or FN.
+type OutsideSubroutineError struct {
+	Message string
+
+	CurTok  token.Token
+	PeekTok token.Token
+}
+
+func (e OutsideSubroutineError) Error() string {
+	return e.Message
+}
+
+// NewOutsideSubroutineError returns a new OutsideSubroutineError.
+func NewOutsideSubroutineError(curTok, peekTok token.Token) OutsideSubroutineError {
+	msg := fmt.Sprintf("'%s' outside of a subroutine (line=%d, startcol=%d, endcol=%d)", curTok.Literal, curTok.Line, curTok.StartCol, curTok.EndCol)
+
+	return OutsideSubroutineError{
+		Message: msg,
+
+		CurTok:  curTok,
+		PeekTok: peekTok,
+	}
+}
+
+// LexError represents an error that was found by the lexer, such as an unterminated string. The lexer reports these as
+// token.ERROR tokens.
+type LexError struct {
+	Message string
+
+	CurTok  token.Token
+	PeekTok token.Token
+}
+
+func (e LexError) Error() string {
+	return e.Message
+}
+
+// NewLexError returns a new LexError from the token.ERROR token.
+func NewLexError(curTok, peekTok token.Token) LexError {
+	return LexError{
+		Message: curTok.Literal,
+
+		CurTok:  curTok,
+		PeekTok: peekTok,
+	}
+}
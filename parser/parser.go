@@ -2,7 +2,9 @@ package parser
 
 import (
 	"errors"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/ollybritton/aqa/ast"
 	"github.com/ollybritton/aqa/lexer"
@@ -26,6 +28,19 @@ type Parser struct {
 
 	prefixParseFns map[token.Type]prefixParseFn
 	infixParseFns  map[token.Type]infixParseFn
+
+	// loopDepth is the number of loops enclosing the current token, used to check that BREAK and CONTINUE only
+	// appear inside loops. It is reset inside subroutine and FN bodies.
+	loopDepth int
+
+	// subroutineDepth is the number of subroutine and FN bodies enclosing the current token, used to check that YIELD
+	// only appears inside one. yielded records whether the innermost body contains a YIELD.
+	subroutineDepth int
+	yielded         bool
+
+	// doc holds the lines of the documentation comment above the current statement, which is attached to it if it is
+	// a subroutine or constant.
+	doc []string
 }
 
 // New returns a new parser from a given lexer.
@@ -33,11 +48,12 @@ func New(l *lexer.Lexer) *Parser {
 	p := &Parser{l: l}
 
 	p.prefixParseFns = map[token.Type]prefixParseFn{
-		token.IDENT: p.parseIdentifier,
-		token.INT:   p.parseIntegerLiteral,
-		token.FLOAT: p.parseFloatLiteral,
-		token.TRUE:  p.parseBooleanLiteral,
-		token.FALSE: p.parseBooleanLiteral,
+		token.IDENT:   p.parseIdentifier,
+		token.INT:     p.parseIntegerLiteral,
+		token.FLOAT:   p.parseFloatLiteral,
+		token.DECIMAL: p.parseDecimalLiteral,
+		token.TRUE:    p.parseBooleanLiteral,
+		token.FALSE:   p.parseBooleanLiteral,
 
 		token.BANG:  p.parsePrefixExpression,
 		token.MINUS: p.parsePrefixExpression,
@@ -48,11 +64,21 @@ func New(l *lexer.Lexer) *Parser {
 		token.LBRACKET: p.parseArrayLiteral,
 		token.STRING:   p.parseStringLiteral,
 
+		token.FSTRING_HEAD: p.parseInterpolatedString,
+
 		token.OUTPUT:    p.parseOutput,
 		token.USERINPUT: p.parseUserinput,
 
 		token.MAP:    p.parseHashLiteral,
 		token.LBRACE: p.parseHashLiteral,
+		token.SET:    p.parseSetLiteral,
+
+		token.FN:    p.parseFunctionLiteral,
+		token.NEW:   p.parseNewExpression,
+		token.SUPER: p.parseSuperExpression,
+		token.IF:    p.parseConditionalExpression,
+
+		token.ERROR: p.parseLexError,
 	}
 
 	p.infixParseFns = map[token.Type]infixParseFn{
@@ -77,6 +103,10 @@ func New(l *lexer.Lexer) *Parser {
 		token.OR:  p.parseInfixExpression,
 		token.XOR: p.parseInfixExpression,
 
+		token.IN:        p.parseInfixExpression,
+		token.PIPE:      p.parseInfixExpression,
+		token.AMPERSAND: p.parseInfixExpression,
+
 		token.LPAREN:   p.parseCallExpression,
 		token.LBRACKET: p.parseIndexExpression,
 	}
@@ -120,6 +150,11 @@ func (p *Parser) Parse() *ast.Program {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	// A documentation comment only documents the statement directly below it, so a blank line discards it.
+	if p.curTokenIs(token.NEWLINE) {
+		p.doc = nil
+	}
 }
 
 func (p *Parser) parseStatement() ast.Statement {
@@ -131,17 +166,29 @@ func (p *Parser) parseStatement() ast.Statement {
 		return nil
 	}
 
+	if p.curTokenIs(token.DOC_COMMENT) {
+		p.doc = append(p.doc, p.curToken.Literal)
+		return nil
+	}
+
+	doc := strings.Join(p.doc, "\n")
+	p.doc = nil
+
 	switch {
 	case p.curToken.Type == token.IDENT && p.peekTokenIs(token.ASSIGN):
 		return p.parseVariableAssignment()
+	case p.curToken.Type == token.IDENT && p.peekTokenIs(token.COMMA):
+		return p.parseMultipleAssignment()
 	case p.curToken.Type == token.CONSTANT && p.peekTokenIs(token.IDENT):
-		return p.parseConstantAssignment()
+		return p.parseConstantAssignment(doc)
 	case p.curToken.Type == token.RETURN:
 		return p.parseReturnStatement()
+	case p.curToken.Type == token.YIELD:
+		return p.parseYieldStatement()
 	case p.curToken.Type == token.IF:
 		return p.parseIfStatement()
 	case p.curToken.Type == token.SUBROUTINE:
-		return p.parseSubroutineDefinition()
+		return p.parseSubroutineDefinition(doc)
 	case p.curToken.Type == token.WHILE:
 		return p.parseWhileStatement()
 	case p.curToken.Type == token.FOR:
@@ -150,6 +197,20 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseRepeatStatement()
 	case p.curToken.Type == token.IMPORT:
 		return p.parseImportStatement()
+	case p.curToken.Type == token.RECORD:
+		return p.parseRecordDefinition()
+	case p.curToken.Type == token.ENUM:
+		return p.parseEnumDefinition()
+	case p.curToken.Type == token.CLASS:
+		return p.parseClassDefinition()
+	case p.curToken.Type == token.BREAK:
+		return p.parseBreakStatement()
+	case p.curToken.Type == token.CONTINUE:
+		return p.parseContinueStatement()
+	case p.curToken.Type == token.TRY:
+		return p.parseTryStatement()
+	case p.curToken.Type == token.THROW:
+		return p.parseThrowStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -175,6 +236,72 @@ func (p *Parser) parseVariableAssignment() *ast.VariableAssignment {
 	return stmt
 }
 
+func (p *Parser) parseMultipleAssignment() ast.Statement {
+	names := []*ast.Identifier{{Tok: p.curToken, Value: p.curToken.Literal}}
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT))
+			return nil
+		}
+
+		names = append(names, &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal})
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.ASSIGN))
+		return nil
+	}
+
+	stmt := &ast.MultipleAssignment{Tok: p.curToken, Names: names}
+	p.nextToken()
+
+	stmt.Values = []ast.Expression{p.parseExpression(LOWEST)}
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+
+		stmt.Values = append(stmt.Values, p.parseExpression(LOWEST))
+	}
+
+	return stmt
+}
+
+// parseYieldStatement parses a YIELD statement. Like RETURN, yielding several values yields them as an array.
+func (p *Parser) parseYieldStatement() ast.Statement {
+	if p.subroutineDepth == 0 {
+		p.addError(NewOutsideSubroutineError(p.curToken, p.peekToken))
+		return nil
+	}
+
+	p.yielded = true
+	stmt := &ast.YieldStatement{Tok: p.curToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.COMMA) {
+		values := &ast.ArrayLiteral{Tok: p.curToken, Elements: []ast.Expression{stmt.Value}}
+
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+
+			values.Elements = append(values.Elements, p.parseExpression(LOWEST))
+		}
+
+		stmt.Value = values
+	}
+
+	return stmt
+}
+
+// parseReturnStatement parses a RETURN statement. Returning several values, such as `RETURN q, r`, returns them as an
+// array so that they can be unpacked by a multiple assignment.
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Tok: p.curToken}
 
@@ -182,10 +309,23 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.COMMA) {
+		values := &ast.ArrayLiteral{Tok: p.curToken, Elements: []ast.Expression{stmt.ReturnValue}}
+
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+
+			values.Elements = append(values.Elements, p.parseExpression(LOWEST))
+		}
+
+		stmt.ReturnValue = values
+	}
+
 	return stmt
 }
 
-func (p *Parser) parseIfStatement() *ast.IfStatement {
+func (p *Parser) parseIfStatement() ast.Statement {
 	stmt := &ast.IfStatement{Tok: p.curToken}
 
 	p.nextToken()
@@ -208,10 +348,20 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 	case p.curTokenIs(token.ELSE) && p.peekTokenIs(token.IF):
 
 		stmt.ElseIf = p.parseElseIfStatement()
+
+		// The chain of ELSE IFs either ends the whole statement or is followed by a final ELSE.
+		if !p.curTokenIs(token.ELSE) {
+			return stmt
+		}
+
 		fallthrough
 
 	case p.curTokenIs(token.ELSE):
 
+		if !p.peekTokenIs(token.NEWLINE) {
+			return p.parseInlineElse(stmt)
+		}
+
 		if p.curTokenIs(token.NEWLINE) {
 			p.nextToken()
 		}
@@ -229,6 +379,79 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 	}
 }
 
+// parseInlineElse parses an ELSE with a statement on the same line. If an ENDIF follows the statement, the whole thing
+// is an IF statement. Otherwise it is a conditional expression used as a statement, such as
+// `IF x > 0 THEN "pos" ELSE "neg"` on the last line of a FN body, so every branch must be a single expression.
+func (p *Parser) parseInlineElse(stmt *ast.IfStatement) ast.Statement {
+	tok := p.curToken
+
+	p.nextToken()
+	alternative := p.parseStatement()
+	if alternative == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+
+	if p.peekTokenIs(token.ENDIF) {
+		p.nextToken()
+		stmt.Else = &ast.BlockStatement{Tok: tok, Statements: []ast.Statement{alternative}}
+
+		return stmt
+	}
+
+	exp := conditionalFromIf(stmt, alternative)
+	if exp == nil {
+		p.addError(
+			NewUnexpectedTokenError(p.curToken, p.peekToken, token.ENDIF),
+		)
+
+		return nil
+	}
+
+	return &ast.ExpressionStatement{Tok: stmt.Tok, Expression: exp}
+}
+
+// conditionalFromIf turns an IF statement and the statement after its final ELSE into the equivalent conditional
+// expression, with each ELSE IF nested inside the alternative of the branch before it. It returns nil if any branch is
+// something other than a single expression.
+func conditionalFromIf(stmt *ast.IfStatement, alternative ast.Statement) ast.Expression {
+	last, ok := alternative.(*ast.ExpressionStatement)
+	if !ok {
+		return nil
+	}
+
+	var branches []*ast.IfStatement
+	for branch := stmt; branch != nil; branch = branch.ElseIf {
+		branches = append(branches, branch)
+	}
+
+	exp := last.Expression
+
+	for i := len(branches) - 1; i >= 0; i-- {
+		branch := branches[i]
+		if branch.Consequence == nil || len(branch.Consequence.Statements) != 1 {
+			return nil
+		}
+
+		consequence, ok := branch.Consequence.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			return nil
+		}
+
+		exp = &ast.ConditionalExpression{
+			Tok:         branch.Tok,
+			Condition:   branch.Condition,
+			Consequence: consequence.Expression,
+			Alternative: exp,
+		}
+	}
+
+	return exp
+}
+
 func (p *Parser) parseElseIfStatement() *ast.IfStatement {
 	stmt := &ast.IfStatement{Tok: p.curToken}
 
@@ -259,8 +482,8 @@ func (p *Parser) parseElseIfStatement() *ast.IfStatement {
 
 }
 
-func (p *Parser) parseSubroutineDefinition() *ast.Subroutine {
-	sub := &ast.Subroutine{Tok: p.curToken}
+func (p *Parser) parseSubroutineDefinition(doc string) *ast.Subroutine {
+	sub := &ast.Subroutine{Tok: p.curToken, Doc: doc}
 	p.nextToken()
 
 	for p.curTokenIs(token.NEWLINE) {
@@ -277,38 +500,135 @@ func (p *Parser) parseSubroutineDefinition() *ast.Subroutine {
 	}
 
 	sub.Parameters = p.parseParameters()
-	sub.Body = p.parseBlockStatement([]token.Type{token.ENDSUBROUTINE})
+	sub.Body, sub.Generator = p.parseSubroutineBody([]token.Type{token.ENDSUBROUTINE})
 
 	p.nextToken()
 
 	return sub
 }
 
-func (p *Parser) parseParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// parseConditionalExpression parses an IF used as an expression, such as `IF x > 0 THEN "pos" ELSE "neg"`. At the start
+// of a statement, an IF is parsed by parseIfStatement instead, which turns it into a conditional expression if it is
+// written on one line without an ENDIF.
+func (p *Parser) parseConditionalExpression() ast.Expression {
+	exp := &ast.ConditionalExpression{Tok: p.curToken}
+
+	p.nextToken()
+	exp.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.THEN) {
+		p.addError(
+			NewUnexpectedTokenError(p.curToken, p.peekToken, token.THEN),
+		)
+
+		return nil
+	}
+
+	p.nextToken()
+	exp.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.ELSE) {
+		p.addError(
+			NewUnexpectedTokenError(p.curToken, p.peekToken, token.ELSE),
+		)
+
+		return nil
+	}
+
+	p.nextToken()
+	exp.Alternative = p.parseExpression(LOWEST)
+
+	return exp
+}
+
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	fn := &ast.FunctionLiteral{Tok: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		p.addError(
+			NewUnexpectedTokenError(p.curToken, p.peekToken, token.LPAREN),
+		)
+
+		return nil
+	}
+
+	fn.Parameters = p.parseParameters()
+
+	if !p.expectPeek(token.LBRACE) {
+		p.addError(
+			NewUnexpectedTokenError(p.curToken, p.peekToken, token.LBRACE),
+		)
+
+		return nil
+	}
+
+	fn.Body, fn.Generator = p.parseSubroutineBody([]token.Type{token.RBRACE})
+
+	return fn
+}
+
+func (p *Parser) parseParameters() []*ast.Parameter {
+	params := []*ast.Parameter{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return params
 	}
 
 	p.nextToken()
-	ident := &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+	params = append(params, p.parseParameter())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
 
-		ident := &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+		params = append(params, p.parseParameter())
 	}
 
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
-	return identifiers
+	for _, param := range params[:len(params)-1] {
+		if param.Rest {
+			p.addError(
+				NewInvalidParameterError(param.Name.Tok, p.curToken, param.String(), "a rest parameter must be the last parameter"),
+			)
+		}
+	}
+
+	return params
+}
+
+func (p *Parser) parseParameter() *ast.Parameter {
+	param := &ast.Parameter{}
+
+	if p.curTokenIs(token.ELLIPSIS) {
+		param.Rest = true
+
+		if !p.expectPeek(token.IDENT) {
+			p.addError(
+				NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT),
+			)
+		}
+	}
+
+	param.Name = &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.ASSIGN) {
+		if param.Rest {
+			p.addError(
+				NewInvalidParameterError(p.curToken, p.peekToken, param.String(), "a rest parameter cannot have a default value"),
+			)
+		}
+
+		p.nextToken()
+		p.nextToken()
+
+		param.Default = p.parseExpression(LOWEST)
+	}
+
+	return param
 }
 
 func (p *Parser) parseBlockStatement(until []token.Type) *ast.BlockStatement {
@@ -337,10 +657,89 @@ func (p *Parser) parseBlockStatement(until []token.Type) *ast.BlockStatement {
 	return block
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+// parseLoopBody parses the body of a loop, inside which BREAK and CONTINUE are allowed.
+func (p *Parser) parseLoopBody(until []token.Type) *ast.BlockStatement {
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+
+	return p.parseBlockStatement(until)
+}
+
+// parseSubroutineBody parses the body of a subroutine or FN, and reports whether it contains a YIELD. A BREAK or
+// CONTINUE inside it can't refer to a loop outside of it, and a YIELD inside a nested subroutine or FN belongs to that
+// instead.
+func (p *Parser) parseSubroutineBody(until []token.Type) (*ast.BlockStatement, bool) {
+	depth, yielded := p.loopDepth, p.yielded
+	p.loopDepth, p.yielded = 0, false
+	p.subroutineDepth++
+
+	defer func() {
+		p.loopDepth, p.yielded = depth, yielded
+		p.subroutineDepth--
+	}()
+
+	return p.parseBlockStatement(until), p.yielded
+}
+
+func (p *Parser) parseBreakStatement() ast.Statement {
+	if p.loopDepth == 0 {
+		p.addError(NewOutsideLoopError(p.curToken, p.peekToken))
+		return nil
+	}
+
+	return &ast.BreakStatement{Tok: p.curToken}
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	if p.loopDepth == 0 {
+		p.addError(NewOutsideLoopError(p.curToken, p.peekToken))
+		return nil
+	}
+
+	return &ast.ContinueStatement{Tok: p.curToken}
+}
+
+func (p *Parser) parseTryStatement() ast.Statement {
+	stmt := &ast.TryStatement{Tok: p.curToken}
+	stmt.Body = p.parseBlockStatement([]token.Type{token.CATCH, token.ENDTRY})
+
+	if !p.curTokenIs(token.CATCH) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.curToken, token.CATCH))
+		return nil
+	}
+
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		stmt.ErrorName = &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+	}
+
+	stmt.Handler = p.parseBlockStatement([]token.Type{token.ENDTRY})
+
+	if !p.curTokenIs(token.ENDTRY) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.curToken, token.ENDTRY))
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseThrowStatement() ast.Statement {
+	stmt := &ast.ThrowStatement{Tok: p.curToken}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	return stmt
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Tok: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.ASSIGN) {
+		return p.parseAssignment(stmt.Expression)
+	}
+
 	if p.peekTokenIs(token.NEWLINE) {
 		p.nextToken()
 	}
@@ -348,6 +747,42 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	return stmt
 }
 
+// parseAssignment parses an assignment to something other than a plain identifier, such as a field of a record or an
+// element of an array. The target has already been parsed as an expression.
+func (p *Parser) parseAssignment(target ast.Expression) ast.Statement {
+	if target == nil {
+		return nil
+	}
+
+	switch target := target.(type) {
+	case *ast.InfixExpression:
+		field, ok := target.Right.(*ast.Identifier)
+		if target.Operator != "." || !ok {
+			break
+		}
+
+		p.nextToken()
+		stmt := &ast.FieldAssignment{Tok: p.curToken, Object: target.Left, Field: field}
+
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+
+		return stmt
+
+	case *ast.IndexExpression:
+		p.nextToken()
+		stmt := &ast.IndexAssignment{Tok: p.curToken, Left: target.Left, Index: target.Index}
+
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+
+		return stmt
+	}
+
+	p.addError(NewInvalidAssignmentTargetError(p.curToken, p.peekToken, target.String()))
+	return nil
+}
+
 // Expression Parsing
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
@@ -381,6 +816,13 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Tok: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if n, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			lit.Big = n
+			return lit
+		}
+	}
+
 	if err != nil {
 		p.addError(
 			NewFloatParseError(p.curToken, p.peekToken, p.curToken.Literal),
@@ -392,6 +834,21 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	lit := &ast.DecimalLiteral{Tok: p.curToken}
+
+	value, ok := new(big.Rat).SetString(strings.TrimRight(p.curToken.Literal, "dD"))
+	if !ok {
+		p.addError(
+			NewDecimalParseError(p.curToken, p.peekToken, p.curToken.Literal),
+		)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
+
 func (p *Parser) parseFloatLiteral() ast.Expression {
 	lit := &ast.FloatLiteral{Tok: p.curToken}
 
@@ -443,12 +900,12 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	}
 
 	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	args = append(args, p.parseCallArgument())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		args = append(args, p.parseCallArgument())
 	}
 
 	if !p.expectPeek(token.RPAREN) {
@@ -458,14 +915,45 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	return args
 }
 
+// parseCallArgument parses a single argument to a subroutine, which may be passed by name or spread from an array.
+func (p *Parser) parseCallArgument() ast.Expression {
+	if p.curTokenIs(token.ELLIPSIS) {
+		spread := &ast.SpreadExpression{Tok: p.curToken}
+		p.nextToken()
+		spread.Value = p.parseExpression(LOWEST)
+
+		return spread
+	}
+
+	if !p.curTokenIs(token.IDENT) || !p.peekTokenIs(token.ASSIGN) {
+		return p.parseExpression(LOWEST)
+	}
+
+	name := &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+
+	arg := &ast.KeywordArgument{Tok: p.curToken, Name: name}
+	p.nextToken()
+	arg.Value = p.parseExpression(LOWEST)
+
+	return arg
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	exp := &ast.PrefixExpression{
 		Tok:      p.curToken,
 		Operator: p.curToken.Literal,
 	}
 
+	// NOT binds looser than the comparisons so that `NOT a = b` negates the whole comparison, whereas the symbolic
+	// prefix operators apply only to the operand immediately after them.
+	precedence := PREFIX
+	if p.curTokenIs(token.NOT) {
+		precedence = NOT
+	}
+
 	p.nextToken()
-	exp.Right = p.parseExpression(PREFIX)
+	exp.Right = p.parseExpression(precedence)
 
 	return exp
 }
@@ -479,11 +967,40 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 
 	precedence := p.curPrecedence()
 	p.nextToken()
+
+	// A constructor is called using SUPER.NEW(...), where NEW is the name of the method rather than the start of a NEW
+	// expression.
+	if expression.Operator == "." && p.curTokenIs(token.NEW) {
+		expression.Right = &ast.Identifier{Tok: p.curToken, Value: "NEW"}
+		return expression
+	}
+
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+func (p *Parser) parseNewExpression() ast.Expression {
+	exp := &ast.NewExpression{Tok: p.curToken}
+
+	// The class is parsed with CALL precedence so that the arguments aren't mistaken for a call to the class itself.
+	p.nextToken()
+	exp.Class = p.parseExpression(CALL)
+
+	if !p.expectPeek(token.LPAREN) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.LPAREN))
+		return nil
+	}
+
+	exp.Arguments = p.parseCallArguments()
+
+	return exp
+}
+
+func (p *Parser) parseSuperExpression() ast.Expression {
+	return &ast.SuperExpression{Tok: p.curToken}
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
 	p.nextToken()
 
@@ -499,6 +1016,37 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Tok: p.curToken, Value: p.curToken.Literal}
 }
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Tok: p.curToken, Strings: []string{p.curToken.Literal}}
+
+	for !p.curTokenIs(token.FSTRING_TAIL) {
+		p.nextToken()
+		str.Expressions = append(str.Expressions, p.parseExpression(LOWEST))
+
+		if p.peekTokenIs(token.ERROR) {
+			p.nextToken()
+			return p.parseLexError()
+		}
+
+		// The lexer only produces the next part of the string once it reaches the `}` closing the expression.
+		if !p.peekTokenIs(token.FSTRING_MID) && !p.peekTokenIs(token.FSTRING_TAIL) {
+			p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.RBRACE))
+			return nil
+		}
+
+		p.nextToken()
+		str.Strings = append(str.Strings, p.curToken.Literal)
+	}
+
+	return str
+}
+
+// parseLexError reports an error found by the lexer, such as an unterminated string.
+func (p *Parser) parseLexError() ast.Expression {
+	p.addError(NewLexError(p.curToken, p.peekToken))
+	return nil
+}
+
 func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	while := &ast.WhileStatement{Tok: p.curToken}
 	p.nextToken()
@@ -508,17 +1056,21 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	if !p.expectPeek(token.NEWLINE) {
 		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.NEWLINE))
 	}
-	while.Body = p.parseBlockStatement([]token.Type{token.ENDWHILE})
+	while.Body = p.parseLoopBody([]token.Type{token.ENDWHILE})
 
 	return while
 }
 
-func (p *Parser) parseForStatement() *ast.ForStatement {
+func (p *Parser) parseForStatement() ast.Statement {
 	stmt := &ast.ForStatement{Tok: p.curToken}
 	p.nextToken()
 
 	stmt.Ident = p.parseIdentifier().(*ast.Identifier)
 
+	if p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA) {
+		return p.parseForInStatement(stmt.Tok, stmt.Ident)
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		p.addError(NewInvalidTokenError(p.curToken, p.peekToken, p.curToken))
 		return &ast.ForStatement{}
@@ -538,11 +1090,49 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	stmt.Lower = lower
 	stmt.Upper = upper
 
+	if p.peekTokenIs(token.STEP) {
+		p.nextToken()
+		p.nextToken()
+
+		stmt.Step = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.NEWLINE) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.NEWLINE))
+	}
+
+	stmt.Body = p.parseLoopBody([]token.Type{token.ENDFOR})
+
+	return stmt
+}
+
+func (p *Parser) parseForInStatement(tok token.Token, first *ast.Identifier) ast.Statement {
+	stmt := &ast.ForInStatement{Tok: tok, Idents: []*ast.Identifier{first}}
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT))
+			return nil
+		}
+
+		stmt.Idents = append(stmt.Idents, p.parseIdentifier().(*ast.Identifier))
+	}
+
+	if !p.expectPeek(token.IN) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IN))
+		return nil
+	}
+	p.nextToken()
+
+	stmt.Iterable = p.parseExpression(LOWEST)
+
 	if !p.expectPeek(token.NEWLINE) {
 		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.NEWLINE))
 	}
 
-	stmt.Body = p.parseBlockStatement([]token.Type{token.ENDFOR})
+	stmt.Body = p.parseLoopBody([]token.Type{token.ENDFOR})
 
 	return stmt
 }
@@ -550,7 +1140,7 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 func (p *Parser) parseRepeatStatement() *ast.RepeatStatement {
 	repeat := &ast.RepeatStatement{Tok: p.curToken}
 
-	repeat.Body = p.parseBlockStatement([]token.Type{token.UNTIL})
+	repeat.Body = p.parseLoopBody([]token.Type{token.UNTIL})
 	p.nextToken()
 
 	repeat.Condition = p.parseExpression(LOWEST)
@@ -606,10 +1196,45 @@ func (p *Parser) parseExpressionList(end token.Type) []ast.Expression {
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Tok: p.curToken, Left: left}
+	tok := p.curToken
+
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(tok, left, index)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.RBRACKET))
+		return nil
+	}
+
+	return &ast.IndexExpression{Tok: tok, Left: left, Index: index}
+}
+
+// parseSliceExpression parses the rest of a slice once the start bound has been read, with the parser sitting just
+// before the first colon.
+func (p *Parser) parseSliceExpression(tok token.Token, left ast.Expression, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Tok: tok, Left: left, Start: start}
 
 	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.RBRACKET))
@@ -619,11 +1244,12 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
-func (p *Parser) parseConstantAssignment() *ast.VariableAssignment {
+func (p *Parser) parseConstantAssignment(doc string) *ast.VariableAssignment {
 	p.nextToken()
 
 	stmt := p.parseVariableAssignment()
 	stmt.Name.Constant = true
+	stmt.Doc = doc
 
 	return stmt
 }
@@ -672,6 +1298,171 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return hash
 }
 
+func (p *Parser) parseSetLiteral() ast.Expression {
+	set := &ast.SetLiteral{Tok: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.LBRACE))
+		return nil
+	}
+
+	set.Elements = p.parseExpressionList(token.RBRACE)
+
+	return set
+}
+
+func (p *Parser) parseRecordDefinition() ast.Statement {
+	stmt := &ast.RecordDefinition{Tok: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT))
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+
+	for {
+		// Documentation comments aren't kept for fields, so they are skipped like ordinary comments.
+		for p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.COMMA) || p.curTokenIs(token.DOC_COMMENT) {
+			p.nextToken()
+		}
+
+		if p.curTokenIs(token.ENDRECORD) {
+			return stmt
+		}
+
+		if !p.curTokenIs(token.IDENT) {
+			p.addError(NewUnexpectedTokenError(p.curToken, p.curToken, token.IDENT))
+			return nil
+		}
+
+		field := &ast.RecordField{Name: &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}}
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+
+			if !p.expectPeek(token.IDENT) {
+				p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT))
+				return nil
+			}
+
+			field.Type = &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+		}
+
+		stmt.Fields = append(stmt.Fields, field)
+		p.nextToken()
+	}
+}
+
+func (p *Parser) parseEnumDefinition() ast.Statement {
+	stmt := &ast.EnumDefinition{Tok: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT))
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+
+	for {
+		// Documentation comments aren't kept for members, so they are skipped like ordinary comments.
+		for p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.COMMA) || p.curTokenIs(token.DOC_COMMENT) {
+			p.nextToken()
+		}
+
+		if p.curTokenIs(token.ENDENUM) {
+			return stmt
+		}
+
+		if !p.curTokenIs(token.IDENT) {
+			p.addError(NewUnexpectedTokenError(p.curToken, p.curToken, token.IDENT))
+			return nil
+		}
+
+		stmt.Members = append(stmt.Members, &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal})
+		p.nextToken()
+	}
+}
+
+func (p *Parser) parseClassDefinition() ast.Statement {
+	stmt := &ast.ClassDefinition{Tok: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT))
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.INHERITS) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			p.addError(NewUnexpectedTokenError(p.curToken, p.peekToken, token.IDENT))
+			return nil
+		}
+
+		stmt.Parent = &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}
+	}
+
+	p.nextToken()
+
+	var doc []string
+
+	for {
+		for p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.DOC_COMMENT) {
+			if p.curTokenIs(token.DOC_COMMENT) {
+				doc = append(doc, p.curToken.Literal)
+			}
+
+			p.nextToken()
+		}
+
+		if p.curTokenIs(token.ENDCLASS) {
+			return stmt
+		}
+
+		private := p.curTokenIs(token.PRIVATE)
+		if p.curTokenIs(token.PUBLIC) || p.curTokenIs(token.PRIVATE) {
+			p.nextToken()
+		}
+
+		switch {
+		case p.curTokenIs(token.SUBROUTINE):
+			sub := p.parseSubroutineDefinition(strings.Join(doc, "\n"))
+			if sub == nil {
+				return nil
+			}
+
+			if sub.Name.Tok.Type == token.NEW {
+				sub.Name.Value = "NEW"
+			}
+
+			stmt.Methods = append(stmt.Methods, &ast.ClassMethod{Subroutine: sub, Private: private})
+
+		case p.curTokenIs(token.IDENT):
+			field := &ast.ClassField{Name: &ast.Identifier{Tok: p.curToken, Value: p.curToken.Literal}, Private: private}
+
+			if p.peekTokenIs(token.ASSIGN) {
+				p.nextToken()
+				p.nextToken()
+				field.Default = p.parseExpression(LOWEST)
+			}
+
+			stmt.Fields = append(stmt.Fields, field)
+			p.nextToken()
+
+		default:
+			p.addError(NewUnexpectedTokenError(p.curToken, p.curToken, token.IDENT))
+			return nil
+		}
+
+		doc = nil
+	}
+}
+
 func (p *Parser) parseImportStatement() *ast.ImportStatement {
 	// import "file.aqa"
 	// import "file.aqa" as otherName
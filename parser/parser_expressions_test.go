@@ -2,9 +2,11 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/ollybritton/aqa/ast"
+	"github.com/ollybritton/aqa/lexer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,6 +55,49 @@ func TestIntegerLiteralExpression(t *testing.T) {
 
 }
 
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	tests := []string{"99999999999999999999", "0x10000000000000000", "9223372036854775808"}
+
+	for _, input := range tests {
+		_, program := parseProgram(t, input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+
+		expected, _ := new(big.Int).SetString(input, 0)
+		if assert.NotNil(t, literal.Big, "literal.Big should be set for %s", input) {
+			assert.Equal(t, 0, expected.Cmp(literal.Big), "literal.Big wrong for %s. got=%s", input, literal.Big)
+		}
+	}
+}
+
+func TestDecimalLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected *big.Rat
+	}{
+		{"0.1d", big.NewRat(1, 10)},
+		{"5d", big.NewRat(5, 1)},
+		{"19.99D", big.NewRat(1999, 100)},
+	}
+
+	for _, tt := range tests {
+		_, program := parseProgram(t, tt.input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.DecimalLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.DecimalLiteral. got=%T", stmt.Expression)
+		}
+
+		assert.Equal(t, 0, tt.expected.Cmp(literal.Value), "literal.Value wrong for %s. got=%s", tt.input, literal.Value)
+		assert.Equal(t, tt.input, literal.String())
+	}
+}
+
 func TestFloatLiteralExpression(t *testing.T) {
 	input := "5.5"
 
@@ -307,7 +352,19 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		},
 		{
 			"NOT true OR false AND false XOR true",
-			"(NOT(((true OR false) AND false) XOR true))",
+			"((NOTtrue) OR ((false AND false) XOR true))",
+		},
+		{
+			"a == 1 AND b == 2",
+			"((a == 1) AND (b == 2))",
+		},
+		{
+			"NOT a == b OR c",
+			"((NOT(a == b)) OR c)",
+		},
+		{
+			"!a == b",
+			"((!a) == b)",
 		},
 	}
 
@@ -321,6 +378,62 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 	}
 }
 
+func TestOperatorPrecedenceTable(t *testing.T) {
+	// Each row is one precedence level, from loosest to tightest.
+	levels := [][]string{
+		{"OR"},
+		{"XOR"},
+		{"AND"},
+		{"==", "!="},
+		{"<", ">", "<=", ">=", "IN"},
+		{"|"},
+		{"&"},
+		{"<<", ">>"},
+		{"+", "-"},
+		{"*", "/"},
+		{"DIV", "MOD"},
+	}
+
+	level := map[string]int{}
+	for i, ops := range levels {
+		for _, op := range ops {
+			level[op] = i
+		}
+	}
+
+	for first, i := range level {
+		for second, j := range level {
+			input := fmt.Sprintf("a %s b %s c", first, second)
+
+			// Operators on the same level are left-associative.
+			expected := fmt.Sprintf("((a %s b) %s c)", first, second)
+			if j > i {
+				expected = fmt.Sprintf("(a %s (b %s c))", first, second)
+			}
+
+			_, program := parseProgram(t, input)
+			assert.Equal(t, expected, program.String(), "input=%q", input)
+		}
+	}
+
+	for op, i := range level {
+		input := fmt.Sprintf("NOT a %s b", op)
+
+		// NOT sits between AND and the comparisons.
+		expected := fmt.Sprintf("(NOT(a %s b))", op)
+		if i <= level["AND"] {
+			expected = fmt.Sprintf("((NOTa) %s b)", op)
+		}
+
+		_, program := parseProgram(t, input)
+		assert.Equal(t, expected, program.String(), "input=%q", input)
+
+		input = fmt.Sprintf("-a %s b", op)
+		_, program = parseProgram(t, input)
+		assert.Equal(t, fmt.Sprintf("((-a) %s b)", op), program.String(), "input=%q", input)
+	}
+}
+
 func TestSubroutineCallParsing(t *testing.T) {
 	input := `add(1, 2 * 3, 4 + 5)`
 
@@ -353,6 +466,170 @@ func TestSubroutineCallParsing(t *testing.T) {
 	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
 }
 
+func TestKeywordArgumentParsing(t *testing.T) {
+	input := `greet("Sam", greeting <- "Hi", times <- 1 + 2)`
+
+	_, program := parseProgram(t, input)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.SubroutineCall)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.SubroutineCall. got=%T", stmt.Expression)
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong number of arguments. got=%d", len(exp.Arguments))
+	}
+
+	assert.Equal(t, `"Sam"`, exp.Arguments[0].String())
+
+	greeting, ok := exp.Arguments[1].(*ast.KeywordArgument)
+	if !ok {
+		t.Fatalf("exp.Arguments[1] is not ast.KeywordArgument. got=%T", exp.Arguments[1])
+	}
+
+	testIdentifier(t, greeting.Name, "greeting")
+	assert.Equal(t, `"Hi"`, greeting.Value.String())
+
+	times, ok := exp.Arguments[2].(*ast.KeywordArgument)
+	if !ok {
+		t.Fatalf("exp.Arguments[2] is not ast.KeywordArgument. got=%T", exp.Arguments[2])
+	}
+
+	testIdentifier(t, times.Name, "times")
+	testInfixExpression(t, times.Value, 1, "+", 2)
+}
+
+func TestSpreadArgumentParsing(t *testing.T) {
+	input := `SUM(1, ...scores, ...[2, 3])`
+
+	_, program := parseProgram(t, input)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.SubroutineCall)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.SubroutineCall. got=%T", stmt.Expression)
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong number of arguments. got=%d", len(exp.Arguments))
+	}
+
+	testLiteralExpression(t, exp.Arguments[0], 1)
+
+	scores, ok := exp.Arguments[1].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("exp.Arguments[1] is not ast.SpreadExpression. got=%T", exp.Arguments[1])
+	}
+
+	testIdentifier(t, scores.Value, "scores")
+
+	literal, ok := exp.Arguments[2].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("exp.Arguments[2] is not ast.SpreadExpression. got=%T", exp.Arguments[2])
+	}
+
+	assert.Equal(t, "[2, 3]", literal.Value.String())
+}
+
+func TestConditionalExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`x <- IF a > 0 THEN "pos" ELSE "neg"`, `x <- (IF (a > 0) THEN "pos" ELSE "neg")`},
+		{`x <- IF a THEN 1 ELSE IF b THEN 2 ELSE 3`, `x <- (IF a THEN 1 ELSE (IF b THEN 2 ELSE 3))`},
+		{`x <- IF a THEN 1 ELSE 2 + 3`, `x <- (IF a THEN 1 ELSE (2 + 3))`},
+		{`f(IF a THEN 1 ELSE 2, 3)`, `f((IF a THEN 1 ELSE 2), 3)`},
+		{`IF a > 0 THEN "pos" ELSE "neg"`, `(IF (a > 0) THEN "pos" ELSE "neg")`},
+		{`IF a THEN 1 ELSE IF b THEN 2 ELSE 3`, `(IF a THEN 1 ELSE (IF b THEN 2 ELSE 3))`},
+		{`FN(x) { IF x > 0 THEN "pos" ELSE "neg" }`, `FN(x) { (IF (x > 0) THEN "pos" ELSE "neg") }`},
+	}
+
+	for _, tt := range tests {
+		_, program := parseProgram(t, tt.input)
+		assert.Equal(t, tt.expected, program.Statements[0].String())
+	}
+}
+
+func TestConditionalExpressionErrors(t *testing.T) {
+	tests := []string{
+		`x <- IF a 1 ELSE 2`,
+		`x <- IF a THEN 1`,
+		`IF a THEN x <- 1 ELSE 2`,
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+			continue
+		}
+
+		if _, ok := p.Errors()[0].(UnexpectedTokenError); !ok {
+			t.Errorf("error is not UnexpectedTokenError. got=%T (%v)", p.Errors()[0], p.Errors()[0])
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `apply(arr, FN(x, y) { x + y })`
+
+	_, program := parseProgram(t, input)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	call, ok := stmt.Expression.(*ast.SubroutineCall)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.SubroutineCall. got=%T", stmt.Expression)
+	}
+
+	if len(call.Arguments) != 2 {
+		t.Fatalf("wrong number of arguments. got=%d", len(call.Arguments))
+	}
+
+	fn, ok := call.Arguments[1].(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("call.Arguments[1] is not ast.FunctionLiteral. got=%T", call.Arguments[1])
+	}
+
+	if len(fn.Parameters) != 2 {
+		t.Fatalf("wrong number of parameters. got=%d", len(fn.Parameters))
+	}
+
+	testLiteralExpression(t, fn.Parameters[0].Name, "x")
+	testLiteralExpression(t, fn.Parameters[1].Name, "y")
+
+	if len(fn.Body.Statements) != 1 {
+		t.Fatalf("fn.Body.Statements does not contain %d statements. got=%d", 1, len(fn.Body.Statements))
+	}
+
+	body, ok := fn.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("fn.Body.Statements[0] is not ast.ExpressionStatement. got=%T", fn.Body.Statements[0])
+	}
+
+	testInfixExpression(t, body.Expression, "x", "+", "y")
+}
+
 func TestStringLiteralExpression(t *testing.T) {
 	input := `'hello\'s world!'`
 
@@ -370,6 +647,27 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStringParsing(t *testing.T) {
+	input := `f"Score: {score}/{total * 2}!"`
+
+	_, program := parseProgram(t, input)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+
+	assert.Equal(t, []string{"Score: ", "/", "!"}, str.Strings)
+
+	if len(str.Expressions) != 2 {
+		t.Fatalf("wrong number of expressions. got=%d", len(str.Expressions))
+	}
+
+	testIdentifier(t, str.Expressions[0], "score")
+	testInfixExpression(t, str.Expressions[1], "total", "*", 2)
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	input := `[1, 2*2, 3+3]`
 
@@ -411,6 +709,71 @@ func TestParsingIndexExpression(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"arr[1:4]", "(arr[1:4])"},
+		{"arr[:3]", "(arr[:3])"},
+		{"arr[2:]", "(arr[2:])"},
+		{"arr[:]", "(arr[:])"},
+		{"arr[::2]", "(arr[::2])"},
+		{"arr[1::-1]", "(arr[1::(-1)])"},
+		{"arr[a + 1:LEN(arr) - 1:2]", "(arr[(a + 1):(LEN(arr) - 1):2])"},
+		{"arr[-1]", "(arr[(-1)])"},
+		{"grid[0][1:][0]", "(((grid[0])[1:])[0])"},
+	}
+
+	for _, tt := range tests {
+		_, program := parseProgram(t, tt.input)
+		assert.Equal(t, tt.expected, program.String(), "input=%q", tt.input)
+	}
+
+	_, program := parseProgram(t, "s[:3]")
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	slice, ok := stmt.Expression.(*ast.SliceExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+	}
+
+	testIdentifier(t, slice.Left, "s")
+	assert.Nil(t, slice.Start)
+	testIntegerLiteral(t, slice.End, 3)
+	assert.Nil(t, slice.Step)
+}
+
+func TestParsingSetLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"SET {}", "SET {}"},
+		{"SET {1, 2 * 2, a}", "SET {1, (2 * 2), a}"},
+		{"set {1} | SET {2} & s - t", "(SET {1} | (SET {2} & (s - t)))"},
+		{"x IN s AND y IN SET {1}", "((x IN s) AND (y IN SET {1}))"},
+		{"NOT x IN s", "(NOT(x IN s))"},
+	}
+
+	for _, tt := range tests {
+		_, program := parseProgram(t, tt.input)
+		assert.Equal(t, tt.expected, program.String(), "input=%q", tt.input)
+	}
+
+	_, program := parseProgram(t, "SET {1, 2}")
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	set, ok := stmt.Expression.(*ast.SetLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.SetLiteral. got=%T", stmt.Expression)
+	}
+
+	assert.Len(t, set.Elements, 2)
+	testIntegerLiteral(t, set.Elements[0], 1)
+	testIntegerLiteral(t, set.Elements[1], 2)
+}
+
 func TestParsingHashLiteralWithStringKeys(t *testing.T) {
 	input := `MAP { 'one': 1, 'two': 2, 'three': 3 }`
 
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/ollybritton/aqa/ast"
+	"github.com/ollybritton/aqa/lexer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -140,7 +141,67 @@ func TestSubroutineParameterParsing(t *testing.T) {
 		}
 
 		for i, ident := range tt.expectedParams {
-			testLiteralExpression(t, sub.Parameters[i], ident)
+			testLiteralExpression(t, sub.Parameters[i].Name, ident)
+		}
+	}
+}
+
+func TestSubroutineDefaultParameters(t *testing.T) {
+	input := `SUBROUTINE greet(name, greeting <- "Hello", times <- 1 + 1) ENDSUBROUTINE`
+
+	_, program := parseProgram(t, input)
+	sub := program.Statements[0].(*ast.Subroutine)
+
+	if len(sub.Parameters) != 3 {
+		t.Fatalf("length of parameters wrong. want=3, got=%d", len(sub.Parameters))
+	}
+
+	testLiteralExpression(t, sub.Parameters[0].Name, "name")
+	assert.Nil(t, sub.Parameters[0].Default, "name should not have a default value")
+
+	testLiteralExpression(t, sub.Parameters[1].Name, "greeting")
+	assert.Equal(t, `"Hello"`, sub.Parameters[1].Default.String())
+
+	testLiteralExpression(t, sub.Parameters[2].Name, "times")
+	testInfixExpression(t, sub.Parameters[2].Default, 1, "+", 1)
+}
+
+func TestSubroutineRestParameters(t *testing.T) {
+	input := `SUBROUTINE total(first, ...rest) ENDSUBROUTINE`
+
+	_, program := parseProgram(t, input)
+	sub := program.Statements[0].(*ast.Subroutine)
+
+	if len(sub.Parameters) != 2 {
+		t.Fatalf("length of parameters wrong. want=2, got=%d", len(sub.Parameters))
+	}
+
+	testLiteralExpression(t, sub.Parameters[0].Name, "first")
+	assert.False(t, sub.Parameters[0].Rest, "first should not be a rest parameter")
+
+	testLiteralExpression(t, sub.Parameters[1].Name, "rest")
+	assert.True(t, sub.Parameters[1].Rest, "rest should be a rest parameter")
+}
+
+func TestInvalidRestParameters(t *testing.T) {
+	tests := []string{
+		`SUBROUTINE total(...rest, last) ENDSUBROUTINE`,
+		`SUBROUTINE total(...rest <- [1]) ENDSUBROUTINE`,
+		`FN(...a, ...b) { a }`,
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.Parse()
+
+		if len(p.Errors()) != 1 {
+			t.Errorf("expected 1 parser error for %q, got %d (%v)", input, len(p.Errors()), p.Errors())
+			continue
+		}
+
+		if _, ok := p.Errors()[0].(InvalidParameterError); !ok {
+			t.Errorf("error is not InvalidParameterError. got=%T (%v)", p.Errors()[0], p.Errors()[0])
 		}
 	}
 }
@@ -240,6 +301,40 @@ ENDIF`
 	}
 }
 
+func TestElseIfWithoutElse(t *testing.T) {
+	input := `IF 1 == 1 THEN
+	a
+ELSE IF 2 == 2 THEN
+	b
+ENDIF
+c`
+	_, program := parseProgram(t, input)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 2, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.IfStatement. got=%T", program.Statements[0])
+	}
+
+	if stmt.ElseIf == nil {
+		t.Fatalf("expected else if in stmt. got=<nil>")
+	}
+
+	if stmt.Else != nil {
+		t.Fatalf("expected no else in stmt. got=%s", stmt.Else)
+	}
+
+	after, ok := program.Statements[1].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not *ast.ExpressionStatement. got=%T", program.Statements[1])
+	}
+
+	testIdentifier(t, after.Expression, "c")
+}
+
 func TestWhileStatement(t *testing.T) {
 	input := `WHILE a != 10
 	a <- a + 1
@@ -345,6 +440,409 @@ ENDFOR`
 
 }
 
+func TestForStatementWithStep(t *testing.T) {
+	input := `FOR i <- 10 TO 0 STEP -2
+  a <- a + 1
+ENDFOR`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+	}
+
+	if stmt.Step == nil {
+		t.Fatalf("for loop step is nil.")
+	}
+
+	if stmt.Step.String() != "(-2)" {
+		t.Fatalf("incorrect for loop step. got=%s, want=%s", stmt.Step.String(), "(-2)")
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("stmt.Body.Statements does not contain %d statements. got=%d", 1, len(stmt.Body.Statements))
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedIdents   []string
+		expectedIterable string
+	}{
+		{"FOR item IN [1, 2, 3]\n  a <- item\nENDFOR", []string{"item"}, "[1, 2, 3]"},
+		{"FOR key, value IN scores\n  a <- value\nENDFOR", []string{"key", "value"}, "scores"},
+	}
+
+	for _, tt := range tests {
+		_, program := parseProgram(t, tt.input)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForInStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForInStatement. got=%T", program.Statements[0])
+		}
+
+		if len(stmt.Idents) != len(tt.expectedIdents) {
+			t.Fatalf("wrong number of loop variables. got=%d, want=%d", len(stmt.Idents), len(tt.expectedIdents))
+		}
+
+		for i, ident := range tt.expectedIdents {
+			testIdentifier(t, stmt.Idents[i], ident)
+		}
+
+		if stmt.Iterable.String() != tt.expectedIterable {
+			t.Fatalf("incorrect for loop iterable. got=%s, want=%s", stmt.Iterable.String(), tt.expectedIterable)
+		}
+
+		if len(stmt.Body.Statements) != 1 {
+			t.Fatalf("stmt.Body.Statements does not contain %d statements. got=%d", 1, len(stmt.Body.Statements))
+		}
+	}
+}
+
+func TestBreakAndContinueStatements(t *testing.T) {
+	input := `WHILE TRUE
+	IF a THEN
+		BREAK
+	ENDIF
+	CONTINUE
+ENDWHILE`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	while, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	if len(while.Body.Statements) != 2 {
+		t.Fatalf("while.Body.Statements does not contain %d statements. got=%d", 2, len(while.Body.Statements))
+	}
+
+	ifStmt, ok := while.Body.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("while.Body.Statements[0] is not ast.IfStatement. got=%T", while.Body.Statements[0])
+	}
+
+	if _, ok := ifStmt.Consequence.Statements[0].(*ast.BreakStatement); !ok {
+		t.Fatalf("ifStmt.Consequence.Statements[0] is not ast.BreakStatement. got=%T", ifStmt.Consequence.Statements[0])
+	}
+
+	if _, ok := while.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Fatalf("while.Body.Statements[1] is not ast.ContinueStatement. got=%T", while.Body.Statements[1])
+	}
+}
+
+func TestBreakAndContinueOutsideLoop(t *testing.T) {
+	tests := []string{
+		"BREAK",
+		"CONTINUE",
+		"IF a THEN\n\tBREAK\nENDIF",
+		"FOR i <- 1 TO 3\nENDFOR\nBREAK",
+		"WHILE TRUE\n\tSUBROUTINE f()\n\t\tBREAK\n\tENDSUBROUTINE\nENDWHILE",
+		"FOR x IN arr\n\tf <- FN() { CONTINUE }\nENDFOR",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+			continue
+		}
+
+		if _, ok := p.Errors()[0].(OutsideLoopError); !ok {
+			t.Errorf("error is not OutsideLoopError. got=%T (%v)", p.Errors()[0], p.Errors()[0])
+		}
+	}
+}
+
+func TestYieldStatement(t *testing.T) {
+	input := `SUBROUTINE squares(n)
+	FOR i <- 1 TO n
+		YIELD i * i
+	ENDFOR
+	double <- FN(x) { x * 2 }
+ENDSUBROUTINE`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	sub, ok := program.Statements[0].(*ast.Subroutine)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.Subroutine. got=%T", program.Statements[0])
+	}
+
+	assert.True(t, sub.Generator)
+
+	loop, ok := sub.Body.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("sub.Body.Statements[0] is not ast.ForStatement. got=%T", sub.Body.Statements[0])
+	}
+
+	yield, ok := loop.Body.Statements[0].(*ast.YieldStatement)
+	if !ok {
+		t.Fatalf("loop.Body.Statements[0] is not ast.YieldStatement. got=%T", loop.Body.Statements[0])
+	}
+
+	assert.Equal(t, "YIELD (i * i)", yield.String())
+
+	assign := sub.Body.Statements[1].(*ast.VariableAssignment)
+	assert.False(t, assign.Value.(*ast.FunctionLiteral).Generator)
+}
+
+func TestYieldOutsideSubroutine(t *testing.T) {
+	tests := []string{
+		"YIELD 1",
+		"FOR i <- 1 TO 3\n\tYIELD i\nENDFOR",
+		"SUBROUTINE f()\nENDSUBROUTINE\nYIELD 1",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+			continue
+		}
+
+		if _, ok := p.Errors()[0].(OutsideSubroutineError); !ok {
+			t.Errorf("error is not OutsideSubroutineError. got=%T (%v)", p.Errors()[0], p.Errors()[0])
+		}
+	}
+}
+
+func TestRecordDefinition(t *testing.T) {
+	input := `RECORD Car
+	make : String
+	price : Real
+	doors
+ENDRECORD`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.RecordDefinition)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.RecordDefinition. got=%T", program.Statements[0])
+	}
+
+	if stmt.Name.Value != "Car" {
+		t.Fatalf("record.Name is not %q. got=%q", "Car", stmt.Name.Value)
+	}
+
+	expected := []struct {
+		name      string
+		fieldType string
+	}{
+		{"make", "String"},
+		{"price", "Real"},
+		{"doors", ""},
+	}
+
+	if len(stmt.Fields) != len(expected) {
+		t.Fatalf("record has wrong number of fields. got=%d, want=%d", len(stmt.Fields), len(expected))
+	}
+
+	for i, field := range expected {
+		testIdentifier(t, stmt.Fields[i].Name, field.name)
+
+		if field.fieldType == "" {
+			assert.Nil(t, stmt.Fields[i].Type, "field %q should have no type", field.name)
+		} else {
+			testIdentifier(t, stmt.Fields[i].Type, field.fieldType)
+		}
+	}
+}
+
+func TestEnumDefinition(t *testing.T) {
+	input := `ENUM Colour
+	RED, GREEN
+	## The colour of the sky.
+	BLUE
+ENDENUM`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.EnumDefinition)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.EnumDefinition. got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Name, "Colour")
+
+	expected := []string{"RED", "GREEN", "BLUE"}
+	if len(stmt.Members) != len(expected) {
+		t.Fatalf("enum has wrong number of members. got=%d, want=%d", len(stmt.Members), len(expected))
+	}
+
+	for i, name := range expected {
+		testIdentifier(t, stmt.Members[i], name)
+	}
+}
+
+func TestClassDefinition(t *testing.T) {
+	input := `CLASS Dog INHERITS Animal
+	PRIVATE tricks <- 0
+	colour
+
+	PUBLIC SUBROUTINE NEW(name)
+		SUPER.NEW(name)
+	ENDSUBROUTINE
+
+	PRIVATE SUBROUTINE bark()
+		OUTPUT "woof"
+	ENDSUBROUTINE
+ENDCLASS`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ClassDefinition)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ClassDefinition. got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Name, "Dog")
+	testIdentifier(t, stmt.Parent, "Animal")
+
+	if len(stmt.Fields) != 2 {
+		t.Fatalf("class has wrong number of fields. got=%d, want=%d", len(stmt.Fields), 2)
+	}
+
+	testIdentifier(t, stmt.Fields[0].Name, "tricks")
+	testIntegerLiteral(t, stmt.Fields[0].Default, 0)
+	assert.True(t, stmt.Fields[0].Private)
+
+	testIdentifier(t, stmt.Fields[1].Name, "colour")
+	assert.Nil(t, stmt.Fields[1].Default)
+	assert.False(t, stmt.Fields[1].Private)
+
+	if len(stmt.Methods) != 2 {
+		t.Fatalf("class has wrong number of methods. got=%d, want=%d", len(stmt.Methods), 2)
+	}
+
+	testIdentifier(t, stmt.Methods[0].Subroutine.Name, "NEW")
+	assert.False(t, stmt.Methods[0].Private)
+	assert.Equal(t, "(SUPER . NEW)(name)", stmt.Methods[0].Subroutine.Body.Statements[0].String())
+
+	testIdentifier(t, stmt.Methods[1].Subroutine.Name, "bark")
+	assert.True(t, stmt.Methods[1].Private)
+}
+
+func TestNewExpression(t *testing.T) {
+	_, program := parseProgram(t, `d <- NEW Dog("Rex", tricks <- 3)`)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	assert.Equal(t, `d <- NEW Dog("Rex", tricks <- 3)`, program.Statements[0].String())
+}
+
+func TestFieldAssignment(t *testing.T) {
+	input := `car.make <- "Tesla"`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.FieldAssignment)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FieldAssignment. got=%T", program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Object, "car")
+	testIdentifier(t, stmt.Field, "make")
+
+	if stmt.Value.String() != `"Tesla"` {
+		t.Fatalf("stmt.Value is not %q. got=%q", `"Tesla"`, stmt.Value.String())
+	}
+}
+
+func TestIndexAssignment(t *testing.T) {
+	input := `grid[i][j + 1] <- 10`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.IndexAssignment)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.IndexAssignment. got=%T", program.Statements[0])
+	}
+
+	inner, ok := stmt.Left.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("stmt.Left is not ast.IndexExpression. got=%T", stmt.Left)
+	}
+
+	testIdentifier(t, inner.Left, "grid")
+	testIdentifier(t, inner.Index, "i")
+	testInfixExpression(t, stmt.Index, "j", "+", 1)
+	testLiteralExpression(t, stmt.Value, 10)
+}
+
+func TestInvalidAssignmentTarget(t *testing.T) {
+	l := lexer.New(`5 <- 10`)
+	p := New(l)
+	p.Parse()
+
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	if _, ok := p.Errors()[0].(InvalidAssignmentTargetError); !ok {
+		t.Fatalf("error is not InvalidAssignmentTargetError. got=%T (%v)", p.Errors()[0], p.Errors()[0])
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []string{
+		`a <- "never closed`,
+		`OUTPUT "bad \u00z0 escape"`,
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.Parse()
+
+		if len(p.Errors()) != 1 {
+			t.Errorf("expected 1 parser error for %q, got %d (%v)", input, len(p.Errors()), p.Errors())
+			continue
+		}
+
+		if _, ok := p.Errors()[0].(LexError); !ok {
+			t.Errorf("error is not LexError. got=%T (%v)", p.Errors()[0], p.Errors()[0])
+		}
+	}
+}
+
 func TestImportStatement(t *testing.T) {
 	input := `IMPORT "randomPath.aqa"`
 
@@ -389,3 +887,215 @@ func testVariableAssignment(t *testing.T, s ast.Statement, expectedName string)
 	return true
 
 }
+
+func TestDocComments(t *testing.T) {
+	input := `## Returns the area of a circle.
+## r is the radius.
+SUBROUTINE area(r)
+	## Not attached to anything.
+	RETURN PI * r * r
+ENDSUBROUTINE
+
+## The ratio of a circle's circumference to its diameter.
+CONSTANT PI <- 3.14
+
+## Detached by the blank line.
+
+SUBROUTINE undocumented()
+	#[ A block
+	comment ]#
+	RETURN 1
+ENDSUBROUTINE
+
+## Only subroutines and constants are documented.
+x <- 5`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d (%v)", len(program.Statements), program.Statements)
+	}
+
+	area, ok := program.Statements[0].(*ast.Subroutine)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.Subroutine. got=%T", program.Statements[0])
+	}
+
+	assert.Equal(t, "Returns the area of a circle.\nr is the radius.", area.Doc)
+	assert.Equal(t, 1, len(area.Body.Statements), "doc comments inside the body should not become statements")
+
+	pi, ok := program.Statements[1].(*ast.VariableAssignment)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not *ast.VariableAssignment. got=%T", program.Statements[1])
+	}
+
+	assert.Equal(t, "The ratio of a circle's circumference to its diameter.", pi.Doc)
+
+	undocumented, ok := program.Statements[2].(*ast.Subroutine)
+	if !ok {
+		t.Fatalf("program.Statements[2] is not *ast.Subroutine. got=%T", program.Statements[2])
+	}
+
+	assert.Equal(t, "", undocumented.Doc)
+	assert.Equal(t, 1, len(undocumented.Body.Statements))
+
+	x, ok := program.Statements[3].(*ast.VariableAssignment)
+	if !ok {
+		t.Fatalf("program.Statements[3] is not *ast.VariableAssignment. got=%T", program.Statements[3])
+	}
+
+	assert.Equal(t, "", x.Doc)
+}
+
+func TestDocCommentsInsideDefinitions(t *testing.T) {
+	input := `scores <- MAP {
+	## The first player.
+	"alice": 1,
+	## The second player.
+	"bob": 2
+}
+
+RECORD Car
+	## Who made it.
+	make : String
+	## How much it costs.
+	price : Real
+ENDRECORD`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d (%v)", len(program.Statements), program.Statements)
+	}
+
+	scores, ok := program.Statements[0].(*ast.VariableAssignment)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.VariableAssignment. got=%T", program.Statements[0])
+	}
+
+	hash, ok := scores.Value.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("scores.Value is not *ast.HashLiteral. got=%T", scores.Value)
+	}
+
+	assert.Equal(t, 2, len(hash.Pairs))
+
+	car, ok := program.Statements[1].(*ast.RecordDefinition)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not *ast.RecordDefinition. got=%T", program.Statements[1])
+	}
+
+	assert.Equal(t, 2, len(car.Fields))
+}
+
+func TestTryStatement(t *testing.T) {
+	input := `TRY
+	a
+CATCH e
+	b
+	c
+ENDTRY
+THROW "oops"`
+
+	_, program := parseProgram(t, input)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d (%v)", len(program.Statements), program.Statements)
+	}
+
+	stmt, ok := program.Statements[0].(*ast.TryStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.TryStatement. got=%T", program.Statements[0])
+	}
+
+	assert.Equal(t, 1, len(stmt.Body.Statements))
+	testIdentifier(t, stmt.ErrorName, "e")
+	assert.Equal(t, 2, len(stmt.Handler.Statements))
+
+	throw, ok := program.Statements[1].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not *ast.ThrowStatement. got=%T", program.Statements[1])
+	}
+
+	assert.Equal(t, `"oops"`, throw.Value.String())
+}
+
+func TestTryStatementWithoutErrorName(t *testing.T) {
+	input := `TRY
+	a
+CATCH
+	b
+ENDTRY`
+
+	_, program := parseProgram(t, input)
+
+	stmt, ok := program.Statements[0].(*ast.TryStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.TryStatement. got=%T", program.Statements[0])
+	}
+
+	assert.Nil(t, stmt.ErrorName, "the error should not have a name")
+	assert.Equal(t, 1, len(stmt.Handler.Statements))
+}
+
+func TestTryStatementErrors(t *testing.T) {
+	tests := []string{
+		"TRY\n\ta\nENDTRY",
+		"TRY\n\ta\nCATCH e\n\tb",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+			continue
+		}
+
+		if _, ok := p.Errors()[0].(UnexpectedTokenError); !ok {
+			t.Errorf("error is not UnexpectedTokenError. got=%T (%v)", p.Errors()[0], p.Errors()[0])
+		}
+	}
+}
+
+func TestMultipleAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a, b <- b, a", "a, b <- b, a"},
+		{"q, r <- divmod(17, 5)", "q, r <- divmod(17, 5)"},
+		{"x, y, z <- 1, 2 + 3, [4]", "x, y, z <- 1, (2 + 3), [4]"},
+	}
+
+	for _, tt := range tests {
+		_, program := parseProgram(t, tt.input)
+
+		stmt, ok := program.Statements[0].(*ast.MultipleAssignment)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not *ast.MultipleAssignment. got=%T", program.Statements[0])
+		}
+
+		assert.Equal(t, tt.expected, stmt.String())
+	}
+}
+
+func TestReturnMultipleValues(t *testing.T) {
+	_, program := parseProgram(t, "RETURN a, b + 1")
+
+	stmt, ok := program.Statements[0].(*ast.ReturnStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.ReturnStatement. got=%T", program.Statements[0])
+	}
+
+	values, ok := stmt.ReturnValue.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("stmt.ReturnValue is not *ast.ArrayLiteral. got=%T", stmt.ReturnValue)
+	}
+
+	if len(values.Elements) != 2 {
+		t.Fatalf("wrong number of return values. got=%d", len(values.Elements))
+	}
+
+	testIdentifier(t, values.Elements[0], "a")
+	testInfixExpression(t, values.Elements[1], "b", "+", 1)
+}
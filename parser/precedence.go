@@ -2,42 +2,51 @@ package parser
 
 import "github.com/ollybritton/aqa/token"
 
-// Definitions of operator precedences.
+// Definitions of operator precedences, from loosest to tightest. The boolean operators sit below the comparisons, as
+// in the AQA specification, so `a = 1 AND b = 2` groups as `(a = 1) AND (b = 2)`.
 const (
 	_ int = iota
 	LOWEST
-	EQUALS      // ==
+	OR          // OR
+	XOR         // XOR
+	AND         // AND
+	NOT         // NOT x
+	EQUALS      // == or !=
+	LESSGREATER // > or < or >= or <= or IN
+	UNION       // |
+	INTERSECT   // &
 	SHIFT       // >> or <<
-	LESSGREATER // > or <
 	SUM         // + or -
 	PRODUCT     // * or /
 	DIVMOD      // DIV or MOD
-	PREFIX      // -X or !X or NOT x
-	BOOLEAN     // OR, AND, XOR
+	PREFIX      // -X or !X
 	CALL        // fn(x)
 	INDEX       // array[index]
 )
 
 // Mappings of precedences to their token types.
 var precedences = map[token.Type]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.LSHIFT:   SHIFT,
-	token.RSHIFT:   SHIFT,
-	token.LT_EQ:    LESSGREATER,
-	token.GT_EQ:    LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.DIV:      DIVMOD,
-	token.MOD:      DIVMOD,
-	token.AND:      BOOLEAN,
-	token.OR:       BOOLEAN,
-	token.XOR:      BOOLEAN,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
-	token.DOT:      INDEX,
+	token.EQ:        EQUALS,
+	token.NOT_EQ:    EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.LT_EQ:     LESSGREATER,
+	token.GT_EQ:     LESSGREATER,
+	token.IN:        LESSGREATER,
+	token.PIPE:      UNION,
+	token.AMPERSAND: INTERSECT,
+	token.LSHIFT:    SHIFT,
+	token.RSHIFT:    SHIFT,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.SLASH:     PRODUCT,
+	token.ASTERISK:  PRODUCT,
+	token.DIV:       DIVMOD,
+	token.MOD:       DIVMOD,
+	token.AND:       AND,
+	token.OR:        OR,
+	token.XOR:       XOR,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  INDEX,
+	token.DOT:       INDEX,
 }
@@ -36,8 +36,10 @@ func (p *Parser) peekPrecedence() int {
 	return LOWEST
 }
 
+// skipNewlines skips over blank lines inside an expression. Documentation comments there have nothing to document, so
+// they are skipped like ordinary comments.
 func (p *Parser) skipNewlines() {
-	for p.curTokenIs(token.NEWLINE) {
+	for p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.DOC_COMMENT) {
 		p.nextToken()
 	}
 }
@@ -81,6 +81,9 @@ func Help() {
 	fmt.Println(
 		au.Sprintf(au.BrightWhite("%q -- %v"), au.White("%buf").Italic(), au.Green("Open up a buffer (in vim) to enter a multiline string")),
 	)
+	fmt.Println(
+		au.Sprintf(au.BrightWhite("%q -- %v"), au.White("%doc name").Italic(), au.Green("Show the documentation comment of a subroutine or constant")),
+	)
 
 	fmt.Println("")
 	fmt.Println("To exit")
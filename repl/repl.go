@@ -65,6 +65,11 @@ func New() *Repl {
 func (r *Repl) Execute(input string) {
 	r.Level = 0
 
+	if strings.HasPrefix(input, "%doc ") {
+		r.Doc(strings.TrimSpace(strings.TrimPrefix(input, "%doc ")))
+		return
+	}
+
 	if strings.HasPrefix(input, "%") {
 		switch input[1:len(input)] {
 		case "lex", "tokenize", "split":
@@ -209,6 +214,26 @@ func (r *Repl) Eval(input string) {
 	fmt.Println("")
 }
 
+// Doc displays the documentation comment of a subroutine or constant defined in the REPL.
+func (r *Repl) Doc(name string) {
+	doc, ok := r.Env.Doc(name)
+	if !ok {
+		fmt.Println(au.Red(au.Bold(
+			fmt.Sprintf("No documentation found for %q.", name),
+		)))
+		fmt.Println("")
+
+		return
+	}
+
+	if obj, ok := r.Env.Get(name); ok && obj.Type() == object.FUNCTION_OBJ {
+		fmt.Println(au.Yellow(obj.Inspect()))
+	}
+
+	fmt.Println(au.Green(doc))
+	fmt.Println("")
+}
+
 // Start starts the REPL.
 func (r *Repl) Start() {
 	Info()
@@ -9,17 +9,42 @@ var suggestions = []prompt.Suggest{
 
 	{Text: "SUBROUTINE", Description: "Define a new subroutine."},
 	{Text: "ENDSUBROUTINE", Description: "End a subroutine."},
+	{Text: "FN", Description: "Define an anonymous function, such as FN(x) { x + 1 }."},
+	{Text: "YIELD", Description: "Produce the next value of a generator from inside a subroutine: YIELD i"},
 	{Text: "CONSTANT", Description: "Define a constant value."},
+	{Text: "RECORD", Description: "Define a new record type."},
+	{Text: "ENDRECORD", Description: "End a record definition."},
+	{Text: "ENUM", Description: "Define a new enum type with a fixed set of values."},
+	{Text: "ENDENUM", Description: "End an enum definition."},
+	{Text: "CLASS", Description: "Define a new class: CLASS Dog INHERITS Animal"},
+	{Text: "ENDCLASS", Description: "End a class definition."},
+	{Text: "PUBLIC", Description: "Make a field or method of a class usable from anywhere."},
+	{Text: "PRIVATE", Description: "Make a field or method of a class only usable inside its methods."},
+	{Text: "NEW", Description: "Create an instance of a class: NEW Dog(\"Rex\")"},
+	{Text: "SUPER", Description: "Call a method of the parent class: SUPER.NEW(name)"},
+	{Text: "SET", Description: "Create a set of distinct values: SET {1, 2, 3}"},
 
 	{Text: "IF", Description: "Start of an if statement."},
 	{Text: "THEN", Description: "Goes after the condition in an if statement."},
 	{Text: "ELSE", Description: "Start of an else block."},
 	{Text: "ENDIF", Description: "End an if statement."},
 
+	{Text: "FOR", Description: "Start of a for loop: FOR i <- 1 TO 10 or FOR item IN list"},
+	{Text: "STEP", Description: "Change how much a for loop counts by: FOR i <- 10 TO 0 STEP -2"},
+	{Text: "IN", Description: "Loop over the items of an array, string, map or set: FOR item IN list, or test membership: x IN list"},
+	{Text: "ENDFOR", Description: "End a for loop."},
+	{Text: "BREAK", Description: "Exit the current loop early."},
+	{Text: "CONTINUE", Description: "Skip to the next iteration of the current loop."},
+	{Text: "TRY", Description: "Start a block whose errors are caught."},
+	{Text: "CATCH", Description: "Handle an error caught by a TRY block."},
+	{Text: "ENDTRY", Description: "End a TRY block."},
+	{Text: "THROW", Description: "Raise an error."},
+
 	{Text: "true", Description: ""},
 	{Text: "false", Description: ""},
 
 	{Text: "%help", Description: "Print some help text."},
+	{Text: "%doc", Description: "Show the documentation comment of a subroutine or constant."},
 
 	{Text: "%lex", Description: "Put the REPL into lex mode."},
 	{Text: "%parse", Description: "Put the REPL into parse mode."},
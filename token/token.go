@@ -34,6 +34,7 @@ func (t Token) String() string {
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	ERROR   = "ERROR" // An error found by the lexer, such as an unterminated string. The literal describes the problem.
 
 	// Identifiers/Literals
 	IDENT  = "IDENT"
@@ -41,6 +42,19 @@ const (
 	STRING = "STRING"
 	FLOAT  = "FLOAT"
 
+	// Decimals, such as 0.1d, are numbers which are stored exactly. The literal includes the trailing "d".
+	DECIMAL = "DECIMAL"
+
+	// Interpolated strings, such as f"a{b}c{d}e", are split into FSTRING_HEAD("a"), the tokens of b, FSTRING_MID("c"),
+	// the tokens of d and then FSTRING_TAIL("e").
+	FSTRING_HEAD = "FSTRING_HEAD"
+	FSTRING_MID  = "FSTRING_MID"
+	FSTRING_TAIL = "FSTRING_TAIL"
+
+	// Documentation comments, such as "## Returns the area of a circle.", are kept so that the parser can attach them to
+	// the subroutine or constant below. The literal is the text of the comment without the leading "##".
+	DOC_COMMENT = "DOC_COMMENT"
+
 	// Operators
 	ASSIGN   = "<-"
 	PLUS     = "+"
@@ -53,6 +67,9 @@ const (
 	LSHIFT   = "<<"
 	RSHIFT   = ">>"
 
+	PIPE      = "|"
+	AMPERSAND = "&"
+
 	LT     = "<"
 	GT     = ">"
 	LT_EQ  = "<="
@@ -60,7 +77,8 @@ const (
 	EQ     = "=="
 	NOT_EQ = "!="
 
-	DOT = "."
+	DOT      = "."
+	ELLIPSIS = "..."
 
 	NOT = "NOT"
 	AND = "AND"
@@ -82,26 +100,52 @@ const (
 
 	// Keywords
 	SUBROUTINE = "SUBROUTINE"
+	FN         = "FN"
 	CONSTANT   = "CONSTANT"
 	TRUE       = "TRUE"
 	FALSE      = "FALSE"
 	IF         = "IF"
 	ELSE       = "ELSE"
 	RETURN     = "RETURN"
+	YIELD      = "YIELD"
 
 	WHILE  = "WHILE"
 	REPEAT = "REPEAT"
 	UNTIL  = "UNTIL"
 	FOR    = "FOR"
 	TO     = "TO"
+	STEP   = "STEP"
+	IN     = "IN"
+
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+
+	TRY    = "TRY"
+	CATCH  = "CATCH"
+	ENDTRY = "ENDTRY"
+	THROW  = "THROW"
 
 	ENDWHILE      = "ENDWHILE"
 	ENDFOR        = "ENDFOR"
 	ENDIF         = "ENDIF"
 	ENDSUBROUTINE = "ENDSUBROUTINE"
-
-	THEN = "THEN"
-	MAP  = "MAP"
+	ENDRECORD     = "ENDRECORD"
+	ENDENUM       = "ENDENUM"
+	ENDCLASS      = "ENDCLASS"
+
+	THEN   = "THEN"
+	MAP    = "MAP"
+	SET    = "SET"
+	RECORD = "RECORD"
+	ENUM   = "ENUM"
+
+	// Classes
+	CLASS    = "CLASS"
+	INHERITS = "INHERITS"
+	PUBLIC   = "PUBLIC"
+	PRIVATE  = "PRIVATE"
+	NEW      = "NEW"
+	SUPER    = "SUPER"
 
 	// Importing
 	// IMPORT "file" (directly accessible)
@@ -132,6 +176,7 @@ func NewToken(tokenType Type, lit string, line, startCol, endCol int) Token {
 var Keywords = map[string]Type{
 	"constant": CONSTANT,
 	"return":   RETURN,
+	"yield":    YIELD,
 
 	"true":  TRUE,
 	"false": FALSE,
@@ -139,6 +184,7 @@ var Keywords = map[string]Type{
 	"if":         IF,
 	"else":       ELSE,
 	"subroutine": SUBROUTINE,
+	"fn":         FN,
 
 	"while":    WHILE,
 	"endwhile": ENDWHILE,
@@ -149,6 +195,16 @@ var Keywords = map[string]Type{
 	"for":    FOR,
 	"endfor": ENDFOR,
 	"to":     TO,
+	"step":   STEP,
+	"in":     IN,
+
+	"break":    BREAK,
+	"continue": CONTINUE,
+
+	"try":    TRY,
+	"catch":  CATCH,
+	"endtry": ENDTRY,
+	"throw":  THROW,
 
 	"then":          THEN,
 	"endif":         ENDIF,
@@ -170,6 +226,21 @@ var Keywords = map[string]Type{
 	"from":   FROM,
 
 	"map": MAP,
+	"set": SET,
+
+	"record":    RECORD,
+	"endrecord": ENDRECORD,
+
+	"enum":    ENUM,
+	"endenum": ENDENUM,
+
+	"class":    CLASS,
+	"endclass": ENDCLASS,
+	"inherits": INHERITS,
+	"public":   PUBLIC,
+	"private":  PRIVATE,
+	"new":      NEW,
+	"super":    SUPER,
 }
 
 // LookupKeyword converts a keyword name into a keyword.